@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/diff"
 	"github.com/sunderee/puby/internal/models"
 	"github.com/sunderee/puby/internal/parsers"
 	"github.com/sunderee/puby/internal/services"
@@ -16,14 +22,51 @@ import (
 const (
 	appName    = "puby"
 	appVersion = "2.0.0"
+
+	// Exit code used when an error stopped the run
+	exitCodeError = 1
+
+	// Exit code used when updates matching --fail-on were found
+	exitCodeUpdatesFound = 2
+
+	// Exit code used when some packages could not be checked
+	exitCodePartialFailure = 3
 )
 
+// statusOutput receives progress and status messages. It is stdout for the
+// text format and stderr otherwise, so stdout only holds the report.
+var statusOutput io.Writer = os.Stdout
+
 func main() {
+	// Run subcommands
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		runCacheCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "align" {
+		runAlignCommand(os.Args[2:])
+		return
+	}
+
 	// Define command-line flags
 	useBetaSDKs := flag.Bool("beta", false, "Use beta versions for SDK updates")
 	checkFlutterSDK := flag.Bool("flutter", false, "Check Flutter SDK version")
 	writeChanges := flag.Bool("write", false, "Write changes to pubspec.yaml (otherwise run in dry-run mode)")
+	verify := flag.Bool("verify", false, "Run pub get after writing changes and revert them if dependencies don't resolve")
+	applyResolvable := flag.Bool("apply-resolvable", false, "Write only the updates pub get still resolves with, bisecting to find the conflicting ones")
+	backup := flag.Bool("backup", false, "Keep the previous pubspec.yaml as pubspec.yaml.bak when writing changes")
+	restore := flag.Bool("restore", false, "Restore pubspec.yaml from the backup of the last write and exit")
+	showDiff := flag.Bool("diff", false, "Show the changes to pubspec.yaml as a unified diff")
 	pubspecPath := flag.String("path", "pubspec.yaml", "Path to the pubspec.yaml file")
+	recursive := flag.Bool("recursive", false, "Check every project below the directory of --path, or the packages of its pub workspace or melos.yaml")
+	concurrency := flag.Int("concurrency", services.DEFAULT_CONCURRENCY, "Maximum number of packages looked up at the same time")
+	disableCache := flag.Bool("no-cache", false, "Do not use cached pub.dev and Flutter release data")
+	offline := flag.Bool("offline", false, "Only use cached data and make no network requests")
+	cacheTTL := flag.Duration("cache-ttl", services.DEFAULT_CACHE_TTL, "How long cached data is used before it is revalidated (e.g. 30m, 12h)")
+	includeTransitive := flag.Bool("transitive", false, "Also check packages that are only depended on indirectly, according to pubspec.lock")
+	formatValue := flag.String("format", string(config.OutputFormatText), "Output format: text, json, sarif, junit or markdown")
+	failOnValue := flag.String("fail-on", "", "Exit with code 2 if updates are found: any, major, minor or sdk")
+	policyValue := flag.String("policy", string(config.UpdatePolicyMajor), "Update policy: patch, minor, major or resolvable (within the current constraint)")
 	showHelp := flag.Bool("help", false, "Show help message")
 	showVersion := flag.Bool("version", false, "Show version information")
 
@@ -47,17 +90,89 @@ func main() {
 		return
 	}
 
+	// Parse the output format first, so every message after this goes to the
+	// right stream
+	outputFormat, err := config.ParseOutputFormat(*formatValue)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+	if outputFormat != config.OutputFormatText {
+		statusOutput = os.Stderr
+	}
+
 	// Resolve the absolute path to pubspec.yaml
 	absPath, err := resolveAbsolutePath(*pubspecPath)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(statusOutput, "Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+
+	// Check if the pubspec.yaml file exists. When checking recursively, the
+	// path may also be the directory to start from.
+	info, err := os.Stat(absPath)
+	if os.IsNotExist(err) {
+		fmt.Fprintf(statusOutput, "Error: pubspec.yaml not found at %s\n", absPath)
+		os.Exit(exitCodeError)
+	}
+	rootDir := filepath.Dir(absPath)
+	if err == nil && info.IsDir() {
+		if !*recursive {
+			fmt.Fprintf(statusOutput, "Error: %s is a directory; use --recursive to check every project in it\n", absPath)
+			os.Exit(exitCodeError)
+		}
+		rootDir = absPath
+	}
+
+	// Fill in the settings that were not given as flags from the environment
+	// and the project configuration
+	projectConfig, err := config.LoadProjectConfig(rootDir)
+	if err != nil {
+		fmt.Fprintf(statusOutput, "Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+	if err := config.ApplyProjectSettings(flag.CommandLine, projectConfig, os.LookupEnv); err != nil {
+		fmt.Fprintf(statusOutput, "Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+
+	if *recursive && *restore {
+		fmt.Fprintln(statusOutput, "Error: --restore can't be combined with --recursive")
+		os.Exit(exitCodeError)
+	}
+
+	// Undo the last write if requested
+	if *restore {
+		fileWriter := services.NewFileWriterService(absPath)
+		if err := fileWriter.RestoreBackup(); err != nil {
+			fmt.Fprintf(statusOutput, "Error: %v\n", err)
+			os.Exit(exitCodeError)
+		}
+		fmt.Fprintf(statusOutput, "Restored %s from its backup\n", absPath)
+		return
+	}
+
+	if *verify && !*writeChanges {
+		fmt.Fprintln(statusOutput, "Error: --verify requires --write")
+		os.Exit(exitCodeError)
 	}
 
-	// Check if the pubspec.yaml file exists
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		fmt.Printf("Error: pubspec.yaml not found at %s\n", absPath)
-		os.Exit(1)
+	// Parse the update policy
+	updatePolicy, err := config.ParseUpdatePolicy(*policyValue)
+	if err != nil {
+		fmt.Fprintf(statusOutput, "Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+
+	// Parse which updates fail the run, if any
+	var failOn *config.FailOn
+	if *failOnValue != "" {
+		parsedFailOn, err := config.ParseFailOn(*failOnValue)
+		if err != nil {
+			fmt.Fprintf(statusOutput, "Error: %v\n", err)
+			os.Exit(exitCodeError)
+		}
+		failOn = &parsedFailOn
 	}
 
 	// Parse include/exclude packages
@@ -78,37 +193,509 @@ func main() {
 		IncludePackages:        includeSlice,
 		ExcludePackages:        excludeSlice,
 		WriteChangesToFile:     writeChanges,
+		BackupOnWrite:          backup,
+		VerifyAfterWrite:       verify,
+		ApplyResolvable:        applyResolvable,
+		UpdatePolicy:           &updatePolicy,
+		OutputFormat:           &outputFormat,
+		FailOn:                 failOn,
+		Concurrency:            concurrency,
+		DisableCache:           disableCache,
+		CacheTTL:               cacheTTL,
+		Offline:                offline,
+		IncludeTransitive:      includeTransitive,
+		PackageRules:           projectConfig.Packages,
+	}
+
+	options := changeOptions{
+		showDiff:        *showDiff,
+		write:           *writeChanges,
+		verify:          *verify,
+		backup:          *backup,
+		applyResolvable: *applyResolvable,
 	}
 
 	// Create services
+	apiService, err := newAPIService(cliConfig)
+	if err != nil {
+		fmt.Fprintf(statusOutput, "Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+	displayService := newDisplayService(outputFormat, absPath)
+
+	// Stop outstanding lookups when interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Check every project of the repository or workspace if requested
+	if *recursive {
+		os.Exit(runRecursive(ctx, rootDir, cliConfig, apiService, displayService, options))
+	}
+
 	pubspecParser := parsers.NewPubspecParser(absPath)
-	apiService := services.NewAPIService()
 	updateService := services.NewUpdateService(pubspecParser, apiService)
-	displayService := services.NewDisplayService()
+	updateService.LockfileParser = parsers.NewLockfileParser(filepath.Join(filepath.Dir(absPath), "pubspec.lock"))
+	updateService.ProjectDir = filepath.Dir(absPath)
+	updateService.GitService = services.NewGitService()
 
 	// Set the config in the update service
 	updateService.Config = cliConfig
 
 	// Check for updates
-	fmt.Printf("Checking for updates in %s...\n", absPath)
-	update, err := updateService.CheckForUpdates()
+	fmt.Fprintf(statusOutput, "Checking for updates in %s...\n", absPath)
+	update, err := updateService.CheckForUpdatesWithContext(ctx)
 	if err != nil {
-		fmt.Printf("Error checking for updates: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(statusOutput, "Error checking for updates: %v\n", err)
+		os.Exit(exitCodeError)
 	}
 
 	// Display the updates
 	displayService.PrintUpdate(update)
 
+	// Show and write the changes to pubspec.yaml
+	if err := applyChanges(absPath, "pubspec.yaml", pubspecParser, update, options); err != nil {
+		reportChangeError(err)
+		os.Exit(exitCodeError)
+	}
+	if update.HasUpdates() && !options.writes() {
+		fmt.Fprintln(statusOutput, "\nRunning in dry-run mode. Use --write flag to apply changes.")
+	}
+
+	// Report partial failure once everything else has been shown and written
+	if len(update.Failures) > 0 {
+		fmt.Fprintf(statusOutput, "\n%d package(s) could not be checked.\n", len(update.Failures))
+		os.Exit(exitCodePartialFailure)
+	}
+
+	// Fail the run if updates were found that should not be there
+	if failOn != nil && services.ShouldFail(update, *failOn) {
+		os.Exit(exitCodeUpdatesFound)
+	}
+}
+
+// changeOptions holds the flags that decide what happens to pubspec.yaml once
+// updates are found
+type changeOptions struct {
+	showDiff        bool
+	write           bool
+	verify          bool
+	backup          bool
+	applyResolvable bool
+}
+
+// writes reports whether the options make changes to pubspec.yaml
+func (o changeOptions) writes() bool {
+	return o.write || o.applyResolvable
+}
+
+// applyChanges shows the changes the updates make to the pubspec.yaml at the
+// given path, and writes them if requested. The name is the path shown in
+// the diff.
+func applyChanges(pubspecFilePath, name string, pubspecParser parsers.PubspecParserInterface, update *models.Update, options changeOptions) error {
+	if !update.HasUpdates() {
+		return nil
+	}
+
+	// Show the changes that are, or would be, written
+	fileWriter := &services.FileWriterService{
+		PubspecFilePath: pubspecFilePath,
+		Backup:          options.backup,
+	}
+	if options.showDiff {
+		if err := printDiff(fileWriter, update, name); err != nil {
+			return fmt.Errorf("failed to prepare diff: %v", err)
+		}
+	}
+
 	// Write changes if needed
-	if *writeChanges && hasUpdates(update) {
-		fileWriter := services.NewFileWriterService(absPath)
-		if err := fileWriter.WriteUpdates(update); err != nil {
-			fmt.Printf("Error writing updates: %v\n", err)
-			os.Exit(1)
+	if options.applyResolvable {
+		result, err := applyResolvableUpdates(fileWriter, pubspecParser, update)
+		if err != nil {
+			return fmt.Errorf("failed to apply updates: %w", err)
+		}
+		printResolutionResult(result)
+	} else if options.write {
+		if err := writeUpdates(fileWriter, pubspecParser, update, options.verify); err != nil {
+			return err
 		}
-		fmt.Println("\nUpdates have been written to pubspec.yaml")
-	} else if hasUpdates(update) {
+		fmt.Fprintf(statusOutput, "\nUpdates have been written to %s\n", name)
+	}
+
+	return nil
+}
+
+// reportChangeError prints why the changes could not be shown or written,
+// including the solver output if the updates did not resolve
+func reportChangeError(err error) {
+	var resolutionError *services.ResolutionError
+	if errors.As(err, &resolutionError) {
+		fmt.Fprintf(statusOutput, "\nThe updates could not be resolved, so pubspec.yaml was left unchanged. Output of %s:\n%s\n",
+			resolutionError.Command, resolutionError.Output)
+		return
+	}
+
+	fmt.Fprintf(statusOutput, "Error: %v\n", err)
+}
+
+// runRecursive checks every project below the root directory, sharing package
+// lookups between them, then shows and writes the changes of each project. It
+// returns the exit code: an error in any project takes precedence over
+// packages that could not be checked, which take precedence over --fail-on.
+func runRecursive(ctx context.Context, rootDir string, cliConfig *config.CLIConfig, apiService services.APIServiceInterface, displayService services.DisplayServiceInterface, options changeOptions) int {
+	pubspecPaths, err := services.DiscoverPubspecs(rootDir)
+	if err != nil {
+		fmt.Fprintf(statusOutput, "Error: %v\n", err)
+		return exitCodeError
+	}
+	if len(pubspecPaths) == 0 {
+		fmt.Fprintf(statusOutput, "Error: no pubspec.yaml found in %s\n", rootDir)
+		return exitCodeError
+	}
+
+	sharedAPIService := services.NewSharedAPIService(apiService)
+	newUpdateService := func(pubspecPath string) *services.UpdateService {
+		updateService := services.NewUpdateService(parsers.NewPubspecParser(pubspecPath), sharedAPIService)
+		updateService.LockfileParser = parsers.NewLockfileParser(services.FindLockfile(filepath.Dir(pubspecPath), rootDir))
+		updateService.ProjectDir = filepath.Dir(pubspecPath)
+		updateService.GitService = services.NewGitService()
+		updateService.Config = cliConfig
+		return updateService
+	}
+
+	fmt.Fprintf(statusOutput, "Checking for updates in %d project(s) in %s...\n", len(pubspecPaths), rootDir)
+	workspaceUpdate, err := services.CheckWorkspace(ctx, rootDir, pubspecPaths, newUpdateService)
+	if err != nil {
+		fmt.Fprintf(statusOutput, "Error checking for updates: %v\n", err)
+		return exitCodeError
+	}
+
+	// Display the updates of all projects
+	displayService.PrintWorkspaceUpdate(workspaceUpdate)
+
+	// Show and write the changes of every project. A project that fails
+	// doesn't stop the others.
+	exitCode := 0
+	for _, project := range workspaceUpdate.Projects {
+		if project.Err != nil {
+			exitCode = exitCodeError
+			continue
+		}
+
+		pubspecFilePath := filepath.Join(rootDir, project.PubspecPath)
+		if err := applyChanges(pubspecFilePath, project.PubspecPath, parsers.NewPubspecParser(pubspecFilePath), project.Update, options); err != nil {
+			fmt.Fprintf(statusOutput, "\n%s:\n", project.PubspecPath)
+			reportChangeError(err)
+			exitCode = exitCodeError
+		}
+	}
+
+	summary := workspaceUpdate.Summary()
+	if summary.ProjectsWithUpdates > 0 && !options.writes() {
+		fmt.Fprintln(statusOutput, "\nRunning in dry-run mode. Use --write flag to apply changes.")
+	}
+
+	// Report partial failure once everything else has been shown and written
+	if exitCode != 0 {
+		return exitCode
+	}
+	if summary.Failures > 0 {
+		fmt.Fprintf(statusOutput, "\n%d package(s) could not be checked.\n", summary.Failures)
+		return exitCodePartialFailure
+	}
+
+	// Fail the run if updates were found that should not be there
+	if cliConfig.FailOn != nil {
+		for _, project := range workspaceUpdate.Projects {
+			if services.ShouldFail(project.Update, *cliConfig.FailOn) {
+				return exitCodeUpdatesFound
+			}
+		}
+	}
+
+	return 0
+}
+
+// writeUpdates writes the updates to pubspec.yaml. With verification, the
+// project is resolved afterwards and the updates are reverted if that fails.
+func writeUpdates(fileWriter *services.FileWriterService, pubspecParser parsers.PubspecParserInterface, update *models.Update, verify bool) error {
+	if !verify {
+		return fileWriter.WriteUpdates(update)
+	}
+
+	pubspec, err := pubspecParser.Parse()
+	if err != nil {
+		return err
+	}
+
+	resolver := services.NewPubResolver(filepath.Dir(fileWriter.PubspecFilePath), pubspec)
+	fmt.Fprintf(statusOutput, "\nVerifying the updates with %s pub get...\n", resolver.Executable)
+
+	return services.WriteAndVerifyUpdates(fileWriter, resolver, update)
+}
+
+// applyResolvableUpdates writes the updates pub get still resolves with,
+// leaving out the ones that conflict
+func applyResolvableUpdates(fileWriter *services.FileWriterService, pubspecParser parsers.PubspecParserInterface, update *models.Update) (*models.ResolutionResult, error) {
+	pubspec, err := pubspecParser.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := services.NewPubResolver(filepath.Dir(fileWriter.PubspecFilePath), pubspec)
+	fmt.Fprintf(statusOutput, "\nApplying the updates that resolve with %s pub get...\n", resolver.Executable)
+
+	return services.ApplyResolvableUpdates(fileWriter, resolver, update)
+}
+
+// printResolutionResult prints which updates were written and which were left
+// out because they conflict, along with the resolver output
+func printResolutionResult(result *models.ResolutionResult) {
+	if result.EnvironmentUpdate != nil || len(result.Applied) > 0 {
+		fmt.Fprintln(statusOutput, "\nUpdates written to pubspec.yaml:")
+		if result.EnvironmentUpdate != nil {
+			printSDKVersions(result.EnvironmentUpdate)
+		}
+		for _, dep := range result.Applied {
+			fmt.Fprintf(statusOutput, "  %s: %s → %s\n", dep.Name, dep.CurrentVersion, dep.LatestVersion)
+		}
+	}
+
+	if result.EnvironmentConflict != nil || len(result.Conflicts) > 0 {
+		fmt.Fprintln(statusOutput, "\nUpdates left out because they don't resolve:")
+		if conflict := result.EnvironmentConflict; conflict != nil {
+			printSDKVersions(&conflict.Update)
+			printResolverOutput(conflict.Reason)
+		}
+		for _, conflict := range result.Conflicts {
+			fmt.Fprintf(statusOutput, "  %s: %s → %s\n", conflict.Update.Name, conflict.Update.CurrentVersion, conflict.Update.LatestVersion)
+			printResolverOutput(conflict.Reason)
+		}
+	}
+}
+
+// printSDKVersions prints the SDK versions of an environment update as part of
+// a resolution result
+func printSDKVersions(env *models.EnvironmentUpdate) {
+	if env.DartSDKVersion != nil {
+		fmt.Fprintf(statusOutput, "  Dart SDK: %s\n", *env.DartSDKVersion)
+	}
+	if env.FlutterSDKVersion != nil {
+		fmt.Fprintf(statusOutput, "  Flutter SDK: %s\n", *env.FlutterSDKVersion)
+	}
+}
+
+// printResolverOutput prints the resolver output explaining a conflict,
+// indented below the update it belongs to
+func printResolverOutput(reason string) {
+	for _, line := range strings.Split(reason, "\n") {
+		fmt.Fprintf(statusOutput, "    %s\n", line)
+	}
+}
+
+// printDiff prints the changes the updates make to pubspec.yaml as a unified
+// diff, colored if it is printed to a terminal
+func printDiff(fileWriter services.FileWriterInterface, update *models.Update, name string) error {
+	original, updated, err := fileWriter.PreviewUpdates(update)
+	if err != nil {
+		return err
+	}
+
+	unifiedDiff := diff.Unified("a/"+name, "b/"+name, string(original), string(updated), diff.DEFAULT_CONTEXT_LINES)
+	if file, ok := statusOutput.(*os.File); ok && isTerminal(file) {
+		unifiedDiff = diff.Colorize(unifiedDiff)
+	}
+
+	fmt.Fprint(statusOutput, unifiedDiff)
+	return nil
+}
+
+// isTerminal reports whether the file is a terminal rather than a pipe or a
+// regular file
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// newDisplayService creates the display service printing the given format.
+// Text is printed without colors if stdout is not a terminal.
+func newDisplayService(format config.OutputFormat, pubspecFilePath string) services.DisplayServiceInterface {
+	switch format {
+	case config.OutputFormatJSON:
+		return services.NewJSONDisplayService()
+	case config.OutputFormatSARIF:
+		return services.NewSARIFDisplayService(pubspecFilePath)
+	case config.OutputFormatJUnit:
+		return services.NewJUnitDisplayService()
+	case config.OutputFormatMarkdown:
+		return services.NewMarkdownDisplayService()
+	}
+
+	return &services.DisplayService{
+		Plain: !isTerminal(os.Stdout),
+	}
+}
+
+// newAPIService creates the API service, authenticating with the tokens from
+// pub-tokens.json, and wraps it in the on-disk cache unless caching is
+// disabled. In offline mode, only the cache is used.
+func newAPIService(cliConfig *config.CLIConfig) (services.APIServiceInterface, error) {
+	isOffline := cliConfig.Offline != nil && *cliConfig.Offline
+	isCacheDisabled := cliConfig.DisableCache != nil && *cliConfig.DisableCache
+	if isOffline && isCacheDisabled {
+		return nil, fmt.Errorf("--offline cannot be combined with --no-cache")
+	}
+
+	tokensPath, err := config.DefaultPubTokensPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate pub-tokens.json: %v", err)
+	}
+	tokens, err := config.LoadPubTokens(tokensPath)
+	if err != nil {
+		return nil, err
+	}
+
+	apiService := services.NewAPIService()
+	apiService.Tokens = tokens
+	if isCacheDisabled {
+		return apiService, nil
+	}
+
+	cacheDir, err := services.DefaultCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %v", err)
+	}
+
+	ttl := services.DEFAULT_CACHE_TTL
+	if cliConfig.CacheTTL != nil {
+		ttl = *cliConfig.CacheTTL
+	}
+
+	cachedAPIService := services.NewCachedAPIService(apiService, cacheDir, ttl)
+	cachedAPIService.Offline = isOffline
+
+	return cachedAPIService, nil
+}
+
+// runCacheCommand runs the "cache" subcommand
+func runCacheCommand(args []string) {
+	if len(args) != 1 || args[0] != "clean" {
+		fmt.Printf("Usage: %s cache clean\n", appName)
+		os.Exit(exitCodeError)
+	}
+
+	cacheDir, err := services.DefaultCacheDir()
+	if err != nil {
+		fmt.Printf("Error: failed to locate cache directory: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+
+	if err := services.CleanCache(cacheDir); err != nil {
+		fmt.Printf("Error: failed to clean cache: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+
+	fmt.Printf("Removed cached data from %s\n", cacheDir)
+}
+
+// runAlignCommand finds dependencies that the projects of a repository or
+// workspace require at different minimum versions, proposes a version to
+// align each of them on, and rewrites the constraints if requested
+func runAlignCommand(args []string) {
+	flags := flag.NewFlagSet("align", flag.ExitOnError)
+	pubspecPath := flags.String("path", ".", "Path to the root directory or root pubspec.yaml of the repository")
+	latest := flags.Bool("latest", false, "Align on the latest version of each package instead of the highest version already required")
+	writeChanges := flags.Bool("write", false, "Rewrite the constraints in every pubspec.yaml (otherwise run in dry-run mode)")
+	showDiff := flags.Bool("diff", false, "Show the changes to every pubspec.yaml as a unified diff")
+	backup := flags.Bool("backup", false, "Keep the previous pubspec.yaml as pubspec.yaml.bak when writing changes")
+	disableCache := flags.Bool("no-cache", false, "Do not use cached pub.dev data")
+	offline := flags.Bool("offline", false, "Only use cached data and make no network requests")
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s align [options]\n\nOptions:\n", appName)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	absPath, err := resolveAbsolutePath(*pubspecPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+	rootDir := absPath
+	if !info.IsDir() {
+		rootDir = filepath.Dir(absPath)
+	}
+
+	pubspecPaths, err := services.DiscoverPubspecs(rootDir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+
+	projectConfig, err := config.LoadProjectConfig(rootDir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+
+	alignService := services.NewAlignService(rootDir, pubspecPaths)
+	alignService.PackageRules = projectConfig.Packages
+	if *latest {
+		apiService, err := newAPIService(&config.CLIConfig{DisableCache: disableCache, Offline: offline})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCodeError)
+		}
+		alignService.APIService = apiService
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("Comparing constraints across %d project(s) in %s...\n", len(pubspecPaths), rootDir)
+	alignments, err := alignService.FindAlignmentsWithContext(ctx)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCodeError)
+	}
+	if len(alignments) == 0 {
+		fmt.Println("All dependencies are aligned!")
+		return
+	}
+	displayService := &services.DisplayService{Plain: !isTerminal(os.Stdout)}
+	displayService.PrintAlignments(alignments)
+
+	// Show and write the changes of every project that needs them
+	updates := services.AlignmentUpdates(alignments)
+	projectPaths := make([]string, 0, len(updates))
+	for projectPath := range updates {
+		projectPaths = append(projectPaths, projectPath)
+	}
+	sort.Strings(projectPaths)
+
+	options := changeOptions{showDiff: *showDiff, write: *writeChanges, backup: *backup}
+	failed := false
+	for _, projectPath := range projectPaths {
+		pubspecFilePath := filepath.Join(rootDir, filepath.FromSlash(projectPath))
+		if err := applyChanges(pubspecFilePath, projectPath, parsers.NewPubspecParser(pubspecFilePath), updates[projectPath], options); err != nil {
+			fmt.Printf("\n%s:\n", projectPath)
+			reportChangeError(err)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(exitCodeError)
+	}
+	if !options.writes() {
 		fmt.Println("\nRunning in dry-run mode. Use --write flag to apply changes.")
 	}
 }
@@ -117,16 +704,43 @@ func main() {
 func printHelp() {
 	fmt.Printf("%s - A utility for managing Dart/Flutter package dependencies\n\n", appName)
 	fmt.Println("Usage:")
-	fmt.Printf("  %s [options]\n\n", appName)
+	fmt.Printf("  %s [options]\n", appName)
+	fmt.Printf("  %s align [options]            # Align constraints of the same dependency across projects\n", appName)
+	fmt.Printf("  %s cache clean                # Remove cached pub.dev and Flutter release data\n\n", appName)
 	fmt.Println("Options:")
 	flag.PrintDefaults()
 	fmt.Println()
+	fmt.Println("Configuration:")
+	fmt.Printf("  These options can also be set in %s next to pubspec.yaml, or under the\n", config.PROJECT_CONFIG_FILE_NAME)
+	fmt.Printf("  puby key of pubspec.yaml, and in environment variables like %s:\n", config.EnvironmentVariableName("fail-on"))
+	fmt.Printf("    %s\n", strings.Join(config.ProjectSettings, ", "))
+	fmt.Println("  Flags take precedence over environment variables, which take precedence")
+	fmt.Println("  over the configuration.")
+	fmt.Println()
+	fmt.Println("Exit codes:")
+	fmt.Println("  0  Up to date, or no updates matching --fail-on")
+	fmt.Println("  1  An error stopped the run")
+	fmt.Println("  2  Updates matching --fail-on were found")
+	fmt.Println("  3  Some packages could not be checked")
+	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Printf("  %s                            # Check for updates in current directory\n", appName)
 	fmt.Printf("  %s --path=/path/to/project    # Check for updates in a specific directory\n", appName)
 	fmt.Printf("  %s --write                    # Apply updates to pubspec.yaml\n", appName)
+	fmt.Printf("  %s --diff                     # Preview the changes to pubspec.yaml\n", appName)
+	fmt.Printf("  %s --write --backup           # Apply updates, keeping pubspec.yaml.bak\n", appName)
+	fmt.Printf("  %s --write --verify           # Apply updates only if pub get still resolves\n", appName)
+	fmt.Printf("  %s --apply-resolvable         # Apply the updates that resolve, skip conflicting ones\n", appName)
+	fmt.Printf("  %s --restore                  # Undo the last write made with --backup\n", appName)
 	fmt.Printf("  %s --include=http,path        # Only check specific packages\n", appName)
 	fmt.Printf("  %s --exclude=flutter_svg      # Check all packages except flutter_svg\n", appName)
+	fmt.Printf("  %s --policy=minor             # Never propose major version bumps\n", appName)
+	fmt.Printf("  %s --format=json              # Print the updates as JSON for scripts\n", appName)
+	fmt.Printf("  %s --format=sarif > puby.sarif # Report updates for code scanning\n", appName)
+	fmt.Printf("  %s --format=markdown          # Print the updates for a pull request description\n", appName)
+	fmt.Printf("  %s --fail-on=major            # Exit with code 2 if a major update is available\n", appName)
+	fmt.Printf("  %s --recursive                # Check every project of a monorepo or workspace\n", appName)
+	fmt.Printf("  %s --transitive               # Also check indirect dependencies from pubspec.lock\n", appName)
 }
 
 // resolveAbsolutePath resolves the absolute path to pubspec.yaml
@@ -163,24 +777,3 @@ func splitCommaSeparatedList(list string) []string {
 
 	return items
 }
-
-// hasUpdates checks if there are any updates
-func hasUpdates(update *models.Update) bool {
-	if update == nil {
-		return false
-	}
-
-	// Check for environment updates
-	if update.EnvironmentUpdate != nil {
-		if update.EnvironmentUpdate.DartSDKVersion != nil || update.EnvironmentUpdate.FlutterSDKVersion != nil {
-			return true
-		}
-	}
-
-	// Check for dependency updates
-	if len(update.DependencyUpdates) > 0 {
-		return true
-	}
-
-	return false
-}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // This configuration is the result of CLI argument parsing. It instructs the
 // tool on its behavior.
 type CLIConfig struct {
@@ -28,4 +30,54 @@ type CLIConfig struct {
 	// pubspec.yaml file. Otherwise, we are running in the dry-run mode and only
 	// printing the changes to the console.
 	WriteChangesToFile *bool
+
+	// If this flag is set, writing the changes keeps the previous pubspec.yaml
+	// as pubspec.yaml.bak, so the write can be undone with --restore.
+	BackupOnWrite *bool
+
+	// If this flag is set, pub get is run after writing the changes, and they
+	// are reverted if the dependencies no longer resolve.
+	VerifyAfterWrite *bool
+
+	// If this flag is set, only the updates pub get still resolves with are
+	// written. Conflicting updates are found by bisection and left out.
+	ApplyResolvable *bool
+
+	// This policy decides which version a dependency is updated to. It is picked
+	// from all published versions of the package rather than only the latest one.
+	// If it's not set, the newest version is proposed, like with UpdatePolicyMajor.
+	UpdatePolicy *UpdatePolicy
+
+	// This is the format the results are printed in. If it's not set, they are
+	// printed as text.
+	OutputFormat *OutputFormat
+
+	// This decides which updates make the run exit with a non-zero code. If it's
+	// not set, finding updates is not treated as a failure.
+	FailOn *FailOn
+
+	// This is the maximum number of package lookups that run at the same time.
+	// If it's not set or lower than one, a sensible default is used.
+	Concurrency *int
+
+	// If this flag is set, responses from pub.dev and the Flutter release feed
+	// are not read from or written to the on-disk cache.
+	DisableCache *bool
+
+	// This is how long cached responses are used before they are revalidated. If
+	// it's not set, a default of one hour is used.
+	CacheTTL *time.Duration
+
+	// If this flag is set, no network requests are made. Everything is checked
+	// against cached data only, and packages without cached data are reported
+	// as unknown.
+	Offline *bool
+
+	// If this flag is set, packages that are only depended on indirectly are
+	// checked as well, using the versions pubspec.lock resolved them to.
+	IncludeTransitive *bool
+
+	// These rules limit how individual packages are checked and updated, by
+	// package name. They come from the project configuration only.
+	PackageRules map[string]PackageRule
 }
@@ -0,0 +1,31 @@
+package config
+
+import "fmt"
+
+// FailOn decides which updates make the run fail, so stale dependencies can
+// break a CI job.
+type FailOn string
+
+const (
+	// Fail on any SDK or dependency update.
+	FailOnAny FailOn = "any"
+
+	// Fail on major dependency updates only.
+	FailOnMajor FailOn = "major"
+
+	// Fail on minor or major dependency updates.
+	FailOnMinor FailOn = "minor"
+
+	// Fail on SDK updates only.
+	FailOnSDK FailOn = "sdk"
+)
+
+// ParseFailOn converts a command-line value into a FailOn.
+func ParseFailOn(value string) (FailOn, error) {
+	switch failOn := FailOn(value); failOn {
+	case FailOnAny, FailOnMajor, FailOnMinor, FailOnSDK:
+		return failOn, nil
+	}
+
+	return "", fmt.Errorf("invalid fail-on value %q (expected any, major, minor or sdk)", value)
+}
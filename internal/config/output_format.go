@@ -0,0 +1,33 @@
+package config
+
+import "fmt"
+
+// OutputFormat decides how the results are printed.
+type OutputFormat string
+
+const (
+	// Colored, human-readable text. This is the default.
+	OutputFormatText OutputFormat = "text"
+
+	// A JSON document following a versioned schema, for scripts and dashboards.
+	OutputFormatJSON OutputFormat = "json"
+
+	// A SARIF log pointing to the lines of pubspec.yaml, for code scanning.
+	OutputFormatSARIF OutputFormat = "sarif"
+
+	// A JUnit XML report with one test case per dependency, for CI systems.
+	OutputFormatJUnit OutputFormat = "junit"
+
+	// Markdown tables that can be pasted into pull request descriptions.
+	OutputFormatMarkdown OutputFormat = "markdown"
+)
+
+// ParseOutputFormat converts a command-line value into an OutputFormat.
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	switch format := OutputFormat(value); format {
+	case OutputFormatText, OutputFormatJSON, OutputFormatSARIF, OutputFormatJUnit, OutputFormatMarkdown:
+		return format, nil
+	}
+
+	return "", fmt.Errorf("invalid output format %q (expected text, json, sarif, junit or markdown)", value)
+}
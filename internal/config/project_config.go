@@ -0,0 +1,239 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sunderee/puby/internal/semver"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PROJECT_CONFIG_FILE_NAME  = "puby.yaml"
+	PROJECT_CONFIG_ENV_PREFIX = "PUBY_"
+)
+
+// ProjectSettings lists the command-line flags that can also be set in the
+// project configuration or the environment. The environment variable of a
+// flag is its name in upper case, with dashes replaced by underscores and
+// prefixed with PUBY_, e.g. PUBY_FAIL_ON for --fail-on.
+var ProjectSettings = []string{
+	"beta",
+	"flutter",
+	"include",
+	"exclude",
+	"policy",
+	"fail-on",
+	"transitive",
+	"concurrency",
+}
+
+// ProjectConfig holds the settings shared by everyone working on a project,
+// read from puby.yaml next to pubspec.yaml or from the puby key of
+// pubspec.yaml itself. Settings that are not set leave the flag defaults in
+// place.
+type ProjectConfig struct {
+	Beta        *bool    `yaml:"beta"`
+	Flutter     *bool    `yaml:"flutter"`
+	Include     []string `yaml:"include"`
+	Exclude     []string `yaml:"exclude"`
+	Policy      string   `yaml:"policy"`
+	FailOn      string   `yaml:"fail-on"`
+	Transitive  *bool    `yaml:"transitive"`
+	Concurrency *int     `yaml:"concurrency"`
+
+	// Packages holds the rules for individual packages, by package name.
+	Packages map[string]PackageRule `yaml:"packages"`
+}
+
+// PackageRule limits how a single package is checked and updated.
+type PackageRule struct {
+	// If this flag is set, the package is never checked, as if it was excluded.
+	Ignore bool `yaml:"ignore"`
+
+	// This constraint pins the package: only versions it allows are proposed,
+	// e.g. "1.4.2" keeps the package at exactly that version.
+	Pin string `yaml:"pin"`
+
+	// This is the highest major version the package may be updated to.
+	MaxMajor *int `yaml:"max-major"`
+}
+
+// Allows reports whether the rule allows updating the package to the version.
+// The zero rule allows every version.
+func (r PackageRule) Allows(version semver.Version) bool {
+	if r.MaxMajor != nil && version.Major > *r.MaxMajor {
+		return false
+	}
+
+	if r.Pin != "" {
+		constraint, err := semver.ParseConstraint(r.Pin)
+		if err != nil || !constraint.Allows(version) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Reason describes how the rule limits the package, e.g. "max-major 1"
+func (r PackageRule) Reason() string {
+	var limits []string
+	if r.Ignore {
+		limits = append(limits, "ignored")
+	}
+	if r.Pin != "" {
+		limits = append(limits, "pin "+r.Pin)
+	}
+	if r.MaxMajor != nil {
+		limits = append(limits, "max-major "+strconv.Itoa(*r.MaxMajor))
+	}
+
+	return strings.Join(limits, ", ")
+}
+
+// LoadProjectConfig reads the project configuration of the project in the
+// given directory: puby.yaml if there is one, the puby key of pubspec.yaml
+// otherwise. A project without either has an empty configuration.
+func LoadProjectConfig(projectDir string) (*ProjectConfig, error) {
+	configPath := filepath.Join(projectDir, PROJECT_CONFIG_FILE_NAME)
+	content, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		var projectConfig ProjectConfig
+		if err := yaml.Unmarshal(content, &projectConfig); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", configPath, err)
+		}
+		if err := projectConfig.validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", configPath, err)
+		}
+
+		return &projectConfig, nil
+	}
+
+	pubspecPath := filepath.Join(projectDir, "pubspec.yaml")
+	content, err = os.ReadFile(pubspecPath)
+	if os.IsNotExist(err) {
+		return &ProjectConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pubspec struct {
+		Puby *ProjectConfig `yaml:"puby"`
+	}
+	if err := yaml.Unmarshal(content, &pubspec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", pubspecPath, err)
+	}
+	if pubspec.Puby == nil {
+		return &ProjectConfig{}, nil
+	}
+	if err := pubspec.Puby.validate(); err != nil {
+		return nil, fmt.Errorf("invalid puby configuration in %s: %v", pubspecPath, err)
+	}
+
+	return pubspec.Puby, nil
+}
+
+// validate checks the package rules, as they have no flags that would
+// validate them later
+func (c *ProjectConfig) validate() error {
+	for packageName, rule := range c.Packages {
+		if rule.Pin != "" {
+			if _, err := semver.ParseConstraint(rule.Pin); err != nil {
+				return fmt.Errorf("invalid pin %q for %s: %v", rule.Pin, packageName, err)
+			}
+		}
+		if rule.MaxMajor != nil && *rule.MaxMajor < 0 {
+			return fmt.Errorf("invalid max-major %d for %s", *rule.MaxMajor, packageName)
+		}
+	}
+
+	return nil
+}
+
+// FlagValues returns the settings of the configuration as command-line flag
+// values, by flag name. Settings that are not set are left out.
+func (c *ProjectConfig) FlagValues() map[string]string {
+	values := make(map[string]string)
+	if c.Beta != nil {
+		values["beta"] = strconv.FormatBool(*c.Beta)
+	}
+	if c.Flutter != nil {
+		values["flutter"] = strconv.FormatBool(*c.Flutter)
+	}
+	if len(c.Include) > 0 {
+		values["include"] = strings.Join(c.Include, ",")
+	}
+	if len(c.Exclude) > 0 {
+		values["exclude"] = strings.Join(c.Exclude, ",")
+	}
+	if c.Policy != "" {
+		values["policy"] = c.Policy
+	}
+	if c.FailOn != "" {
+		values["fail-on"] = c.FailOn
+	}
+	if c.Transitive != nil {
+		values["transitive"] = strconv.FormatBool(*c.Transitive)
+	}
+	if c.Concurrency != nil {
+		values["concurrency"] = strconv.Itoa(*c.Concurrency)
+	}
+
+	return values
+}
+
+// EnvironmentFlagValues returns the project settings set in the environment as
+// command-line flag values, by flag name
+func EnvironmentFlagValues(lookupEnv func(string) (string, bool)) map[string]string {
+	values := make(map[string]string)
+	for _, name := range ProjectSettings {
+		if value, ok := lookupEnv(EnvironmentVariableName(name)); ok {
+			values[name] = value
+		}
+	}
+
+	return values
+}
+
+// EnvironmentVariableName returns the environment variable that sets the
+// command-line flag with the given name
+func EnvironmentVariableName(flagName string) string {
+	return PROJECT_CONFIG_ENV_PREFIX + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// ApplyProjectSettings sets the flags of the set that were not given on the
+// command line from their environment variable or, failing that, the project
+// configuration. The flag set must have been parsed already.
+func ApplyProjectSettings(flagSet *flag.FlagSet, projectConfig *ProjectConfig, lookupEnv func(string) (string, bool)) error {
+	givenFlags := make(map[string]bool)
+	flagSet.Visit(func(f *flag.Flag) {
+		givenFlags[f.Name] = true
+	})
+
+	values := projectConfig.FlagValues()
+	for name, value := range EnvironmentFlagValues(lookupEnv) {
+		values[name] = value
+	}
+
+	for _, name := range ProjectSettings {
+		value, ok := values[name]
+		if !ok || givenFlags[name] {
+			continue
+		}
+
+		if err := flagSet.Set(name, value); err != nil {
+			return fmt.Errorf("invalid %s setting %q: %v", name, value, err)
+		}
+	}
+
+	return nil
+}
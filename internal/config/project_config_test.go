@@ -0,0 +1,242 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/sunderee/puby/internal/semver"
+)
+
+func TestLoadProjectConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected *ProjectConfig
+		err      string
+	}{
+		{
+			name:     "no configuration",
+			files:    map[string]string{"pubspec.yaml": "name: app\n"},
+			expected: &ProjectConfig{},
+		},
+		{
+			name:     "no pubspec.yaml",
+			expected: &ProjectConfig{},
+		},
+		{
+			name: "puby.yaml",
+			files: map[string]string{
+				"puby.yaml": "policy: minor\nexclude:\n  - flutter_svg\npackages:\n  http:\n    max-major: 1\n",
+			},
+			expected: &ProjectConfig{
+				Policy:   "minor",
+				Exclude:  []string{"flutter_svg"},
+				Packages: map[string]PackageRule{"http": {MaxMajor: intPtr(1)}},
+			},
+		},
+		{
+			name:     "puby key of pubspec.yaml",
+			files:    map[string]string{"pubspec.yaml": "name: app\npuby:\n  fail-on: major\n"},
+			expected: &ProjectConfig{FailOn: "major"},
+		},
+		{
+			name: "puby.yaml takes precedence over pubspec.yaml",
+			files: map[string]string{
+				"puby.yaml":    "policy: patch\n",
+				"pubspec.yaml": "name: app\npuby:\n  policy: minor\n  fail-on: major\n",
+			},
+			expected: &ProjectConfig{Policy: "patch"},
+		},
+		{
+			name:  "invalid pin",
+			files: map[string]string{"puby.yaml": "packages:\n  http:\n    pin: not a version\n"},
+			err:   "invalid pin",
+		},
+		{
+			name:  "negative max-major",
+			files: map[string]string{"pubspec.yaml": "name: app\npuby:\n  packages:\n    http:\n      max-major: -1\n"},
+			err:   "invalid max-major -1 for http",
+		},
+		{
+			name:  "malformed puby.yaml",
+			files: map[string]string{"puby.yaml": "policy: [minor\n"},
+			err:   "failed to parse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			for name, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644))
+			}
+
+			projectConfig, err := LoadProjectConfig(projectDir)
+
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, projectConfig)
+		})
+	}
+}
+
+func TestPackageRule_Allows(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     PackageRule
+		version  string
+		expected bool
+	}{
+		{"zero rule", PackageRule{}, "3.0.0", true},
+		{"below max major", PackageRule{MaxMajor: intPtr(1)}, "1.9.0", true},
+		{"above max major", PackageRule{MaxMajor: intPtr(1)}, "2.0.0", false},
+		{"within pin", PackageRule{Pin: "^1.2.0"}, "1.4.0", true},
+		{"outside pin", PackageRule{Pin: "^1.2.0"}, "2.0.0", false},
+		{"invalid pin", PackageRule{Pin: "not a version"}, "1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.rule.Allows(semver.MustParse(tt.version)))
+		})
+	}
+}
+
+func TestProjectConfig_FlagValues(t *testing.T) {
+	beta := true
+	concurrency := 4
+	projectConfig := &ProjectConfig{
+		Beta:        &beta,
+		Include:     []string{"http", "path"},
+		Policy:      "minor",
+		Concurrency: &concurrency,
+	}
+
+	assert.Equal(t, map[string]string{
+		"beta":        "true",
+		"include":     "http,path",
+		"policy":      "minor",
+		"concurrency": "4",
+	}, projectConfig.FlagValues())
+	assert.Empty(t, (&ProjectConfig{}).FlagValues())
+}
+
+func TestEnvironmentVariableName(t *testing.T) {
+	tests := []struct {
+		flagName string
+		expected string
+	}{
+		{"beta", "PUBY_BETA"},
+		{"fail-on", "PUBY_FAIL_ON"},
+		{"concurrency", "PUBY_CONCURRENCY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flagName, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EnvironmentVariableName(tt.flagName))
+		})
+	}
+}
+
+func TestEnvironmentFlagValues(t *testing.T) {
+	environment := map[string]string{
+		"PUBY_FAIL_ON":    "major",
+		"PUBY_TRANSITIVE": "true",
+		"PUBY_UNKNOWN":    "ignored",
+		"FAIL_ON":         "ignored",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, ok := environment[name]
+		return value, ok
+	}
+
+	assert.Equal(t, map[string]string{
+		"fail-on":    "major",
+		"transitive": "true",
+	}, EnvironmentFlagValues(lookupEnv))
+}
+
+func TestApplyProjectSettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		config      *ProjectConfig
+		environment map[string]string
+		expected    string
+		err         string
+	}{
+		{name: "default", config: &ProjectConfig{}, expected: "major"},
+		{name: "configuration", config: &ProjectConfig{Policy: "minor"}, expected: "minor"},
+		{
+			name:        "environment over configuration",
+			config:      &ProjectConfig{Policy: "minor"},
+			environment: map[string]string{"PUBY_POLICY": "patch"},
+			expected:    "patch",
+		},
+		{
+			name:        "flag over environment and configuration",
+			args:        []string{"--policy=resolvable"},
+			config:      &ProjectConfig{Policy: "minor"},
+			environment: map[string]string{"PUBY_POLICY": "patch"},
+			expected:    "resolvable",
+		},
+		{
+			name:        "invalid environment value",
+			config:      &ProjectConfig{},
+			environment: map[string]string{"PUBY_POLICY": "sometimes"},
+			err:         `invalid policy setting "sometimes"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := "major"
+			flagSet := flag.NewFlagSet("puby", flag.ContinueOnError)
+			flagSet.Var(policyValue{&policy}, "policy", "")
+			require.NoError(t, flagSet.Parse(tt.args))
+
+			err := ApplyProjectSettings(flagSet, tt.config, func(name string) (string, bool) {
+				value, ok := tt.environment[name]
+				return value, ok
+			})
+
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+// policyValue is a flag value that only accepts update policies
+type policyValue struct {
+	policy *string
+}
+
+func (v policyValue) String() string {
+	if v.policy == nil {
+		return ""
+	}
+	return *v.policy
+}
+
+func (v policyValue) Set(value string) error {
+	if _, err := ParseUpdatePolicy(value); err != nil {
+		return err
+	}
+	*v.policy = value
+	return nil
+}
+
+func intPtr(value int) *int {
+	return &value
+}
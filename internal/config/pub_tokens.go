@@ -0,0 +1,81 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PubTokens holds the credentials for private package repositories, in the
+// format `dart pub token add` writes to pub-tokens.json.
+type PubTokens struct {
+	Version int        `json:"version"`
+	Hosted  []PubToken `json:"hosted"`
+}
+
+// PubToken is the credential for a single package repository. The token is
+// either stored directly or read from the named environment variable.
+type PubToken struct {
+	URL   string `json:"url"`
+	Token string `json:"token,omitempty"`
+	Env   string `json:"env,omitempty"`
+}
+
+// DefaultPubTokensPath returns the location the Dart SDK stores pub-tokens.json
+// in, inside the user's configuration directory.
+func DefaultPubTokensPath() (string, error) {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(userConfigDir, "dart", "pub-tokens.json"), nil
+}
+
+// LoadPubTokens reads pub-tokens.json from the given path. A missing file is
+// not an error and results in no tokens.
+func LoadPubTokens(path string) (*PubTokens, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &PubTokens{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens PubTokens
+	if err := json.Unmarshal(content, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	return &tokens, nil
+}
+
+// TokenFor returns the bearer token for a request URL, i.e. the token of the
+// most specific repository URL the request URL falls under. It returns an
+// empty string if there is no matching token.
+func (t *PubTokens) TokenFor(requestURL string) string {
+	token := ""
+	matchLength := 0
+	for _, hosted := range t.Hosted {
+		repositoryURL := strings.TrimRight(hosted.URL, "/")
+		if repositoryURL == "" || len(repositoryURL) <= matchLength {
+			continue
+		}
+		if requestURL != repositoryURL && !strings.HasPrefix(requestURL, repositoryURL+"/") {
+			continue
+		}
+
+		value := hosted.Token
+		if hosted.Env != "" {
+			value = os.Getenv(hosted.Env)
+		}
+
+		token = value
+		matchLength = len(repositoryURL)
+	}
+
+	return token
+}
@@ -0,0 +1,31 @@
+package config
+
+import "fmt"
+
+// UpdatePolicy limits how far a dependency may be updated relative to the
+// version its constraint currently points at.
+type UpdatePolicy string
+
+const (
+	// Only take newer patch releases of the same major and minor version.
+	UpdatePolicyPatch UpdatePolicy = "patch"
+
+	// Take newer minor and patch releases of the same major version.
+	UpdatePolicyMinor UpdatePolicy = "minor"
+
+	// Take the newest release, including major bumps. This is the default.
+	UpdatePolicyMajor UpdatePolicy = "major"
+
+	// Take the newest release still allowed by the existing constraint.
+	UpdatePolicyResolvable UpdatePolicy = "resolvable"
+)
+
+// ParseUpdatePolicy converts a command-line value into an UpdatePolicy.
+func ParseUpdatePolicy(value string) (UpdatePolicy, error) {
+	switch policy := UpdatePolicy(value); policy {
+	case UpdatePolicyPatch, UpdatePolicyMinor, UpdatePolicyMajor, UpdatePolicyResolvable:
+		return policy, nil
+	}
+
+	return "", fmt.Errorf("invalid update policy %q (expected patch, minor, major or resolvable)", value)
+}
@@ -0,0 +1,216 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DEFAULT_CONTEXT_LINES is the number of unchanged lines shown around changes
+const DEFAULT_CONTEXT_LINES = 3
+
+// opKind tells whether a line is kept, removed or added
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is a single line of the edit script turning the old text into the new one
+type op struct {
+	kind opKind
+	line string
+
+	// oldIndex and newIndex are the zero-based positions of the line in the
+	// old and new text. For added lines, oldIndex is where they are inserted,
+	// and for removed lines, newIndex likewise.
+	oldIndex int
+	newIndex int
+}
+
+// Unified returns a unified diff turning the old text into the new one, with
+// the given number of unchanged context lines around each change. It returns
+// an empty string if the texts are equal. Carriage returns at the end of lines
+// are not shown, so CRLF files produce the same diff as LF files.
+func Unified(oldName, newName, oldText, newText string, contextLines int) string {
+	if oldText == newText {
+		return ""
+	}
+
+	oldLines, oldHasFinalNewline := splitLines(oldText)
+	newLines, newHasFinalNewline := splitLines(newText)
+	ops := editScript(oldLines, newLines)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %s\n+++ %s\n", oldName, newName)
+
+	for _, hunk := range hunks(ops, contextLines) {
+		oldStart, oldCount, newStart, newCount := hunkRange(hunk)
+		fmt.Fprintf(&builder, "@@ -%s +%s @@\n", formatRange(oldStart, oldCount), formatRange(newStart, newCount))
+
+		for _, o := range hunk {
+			prefix := " "
+			switch o.kind {
+			case opDelete:
+				prefix = "-"
+			case opInsert:
+				prefix = "+"
+			}
+			builder.WriteString(prefix + strings.TrimSuffix(o.line, "\r") + "\n")
+
+			// Mark the last line of a text that doesn't end with a newline
+			isLastOld := o.kind != opInsert && o.oldIndex == len(oldLines)-1 && !oldHasFinalNewline
+			isLastNew := o.kind != opDelete && o.newIndex == len(newLines)-1 && !newHasFinalNewline
+			if isLastOld || isLastNew {
+				builder.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return builder.String()
+}
+
+// splitLines splits a text into lines and reports whether it ends with a
+// newline
+func splitLines(text string) ([]string, bool) {
+	if text == "" {
+		return nil, true
+	}
+
+	hasFinalNewline := strings.HasSuffix(text, "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n"), hasFinalNewline
+}
+
+// editScript computes the shortest sequence of kept, removed and added lines
+// turning the old lines into the new ones, from their longest common
+// subsequence
+func editScript(oldLines, newLines []string) []op {
+	// lengths[i][j] is the length of the longest common subsequence of
+	// oldLines[i:] and newLines[j:]
+	lengths := make([][]int, len(oldLines)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			ops = append(ops, op{kind: opEqual, line: oldLines[i], oldIndex: i, newIndex: j})
+			i++
+			j++
+		case j == len(newLines) || (i < len(oldLines) && lengths[i+1][j] >= lengths[i][j+1]):
+			ops = append(ops, op{kind: opDelete, line: oldLines[i], oldIndex: i, newIndex: j})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, line: newLines[j], oldIndex: i, newIndex: j})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// hunks groups the changes of an edit script with their surrounding context
+// lines. Changes that are close enough to share context end up in one hunk.
+func hunks(ops []op, contextLines int) [][]op {
+	var result [][]op
+	start, end := -1, -1
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+
+		hunkStart := max(i-contextLines, 0)
+		hunkEnd := min(i+contextLines+1, len(ops))
+		if start != -1 && hunkStart <= end {
+			end = hunkEnd
+			continue
+		}
+
+		if start != -1 {
+			result = append(result, ops[start:end])
+		}
+		start, end = hunkStart, hunkEnd
+	}
+
+	if start != -1 {
+		result = append(result, ops[start:end])
+	}
+
+	return result
+}
+
+// hunkRange returns the one-based start line and the number of lines a hunk
+// covers in the old and new text
+func hunkRange(hunk []op) (int, int, int, int) {
+	oldStart, newStart := hunk[0].oldIndex+1, hunk[0].newIndex+1
+	oldCount, newCount := 0, 0
+	for _, o := range hunk {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+
+	// An empty range starts at the line before it
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	return oldStart, oldCount, newStart, newCount
+}
+
+// formatRange formats the range of a hunk header, leaving out a count of one
+func formatRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// Colorize colors the lines of a unified diff for display in a terminal
+func Colorize(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		body := strings.TrimSuffix(line, "\n")
+		if body == "" {
+			continue
+		}
+
+		color := ""
+		switch {
+		case strings.HasPrefix(body, "---"), strings.HasPrefix(body, "+++"):
+			color = "\033[1m"
+		case strings.HasPrefix(body, "@@"):
+			color = "\033[0;36m"
+		case strings.HasPrefix(body, "-"):
+			color = "\033[0;31m"
+		case strings.HasPrefix(body, "+"):
+			color = "\033[0;32m"
+		}
+
+		if color != "" {
+			lines[i] = color + body + "\033[0m" + line[len(body):]
+		}
+	}
+
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		newText  string
+		context  int
+		expected string
+	}{
+		{
+			name:     "equal texts",
+			oldText:  "a\nb\n",
+			newText:  "a\nb\n",
+			context:  3,
+			expected: "",
+		},
+		{
+			name:    "changed line with context",
+			oldText: "name: app\nenvironment:\n  sdk: ^3.0.0\ndependencies:\n  http: ^1.1.0\n  path: ^1.8.0\n",
+			newText: "name: app\nenvironment:\n  sdk: ^3.0.0\ndependencies:\n  http: ^1.2.0\n  path: ^1.8.0\n",
+			context: 1,
+			expected: "--- a/pubspec.yaml\n+++ b/pubspec.yaml\n" +
+				"@@ -4,3 +4,3 @@\n" +
+				" dependencies:\n" +
+				"-  http: ^1.1.0\n" +
+				"+  http: ^1.2.0\n" +
+				"   path: ^1.8.0\n",
+		},
+		{
+			name:    "separate hunks",
+			oldText: "a\nb\nc\nd\ne\nf\ng\n",
+			newText: "A\nb\nc\nd\ne\nf\nG\n",
+			context: 1,
+			expected: "--- a/pubspec.yaml\n+++ b/pubspec.yaml\n" +
+				"@@ -1,2 +1,2 @@\n-a\n+A\n b\n" +
+				"@@ -6,2 +6,2 @@\n f\n-g\n+G\n",
+		},
+		{
+			name:    "added and removed lines",
+			oldText: "a\nb\n",
+			newText: "a\nx\ny\n",
+			context: 0,
+			expected: "--- a/pubspec.yaml\n+++ b/pubspec.yaml\n" +
+				"@@ -2 +2,2 @@\n-b\n+x\n+y\n",
+		},
+		{
+			name:    "pure insertion",
+			oldText: "a\nb\n",
+			newText: "a\nx\nb\n",
+			context: 0,
+			expected: "--- a/pubspec.yaml\n+++ b/pubspec.yaml\n" +
+				"@@ -1,0 +2 @@\n+x\n",
+		},
+		{
+			name:    "CRLF line endings",
+			oldText: "a\r\nb\r\n",
+			newText: "a\r\nc\r\n",
+			context: 3,
+			expected: "--- a/pubspec.yaml\n+++ b/pubspec.yaml\n" +
+				"@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+		},
+		{
+			name:    "no newline at end of file",
+			oldText: "a\nb",
+			newText: "a\nc",
+			context: 3,
+			expected: "--- a/pubspec.yaml\n+++ b/pubspec.yaml\n" +
+				"@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Unified("a/pubspec.yaml", "b/pubspec.yaml", tt.oldText, tt.newText, tt.context)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestColorize(t *testing.T) {
+	diff := "--- a/pubspec.yaml\n+++ b/pubspec.yaml\n@@ -1 +1 @@\n-a\n+b\n c\n"
+
+	assert.Equal(t,
+		"\033[1m--- a/pubspec.yaml\033[0m\n"+
+			"\033[1m+++ b/pubspec.yaml\033[0m\n"+
+			"\033[0;36m@@ -1 +1 @@\033[0m\n"+
+			"\033[0;31m-a\033[0m\n"+
+			"\033[0;32m+b\033[0m\n"+
+			" c\n",
+		Colorize(diff))
+}
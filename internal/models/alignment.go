@@ -0,0 +1,33 @@
+package models
+
+// Alignment is a hosted dependency that the projects of a workspace require
+// at different minimum versions, along with the version to align them on.
+type Alignment struct {
+	Name string
+
+	// HostedURL is the package repository the dependency comes from. It is
+	// empty for the default repository.
+	HostedURL string
+
+	TargetVersion string
+	Usages        []AlignmentUsage
+}
+
+// AlignmentUsage is the declaration of a dependency in one project.
+type AlignmentUsage struct {
+	// PubspecPath is the path of the project's pubspec.yaml, relative to the
+	// workspace root.
+	PubspecPath string
+
+	Section    DependencySection
+	Constraint string
+
+	// Version is the minimum version the constraint allows.
+	Version string
+
+	// TargetVersion is the version this project is aligned on if a puby:max
+	// annotation holds the dependency back below the alignment's target
+	// version, with Reason explaining why. Both are empty otherwise.
+	TargetVersion string
+	Reason        string
+}
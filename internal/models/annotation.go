@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sunderee/puby/internal/semver"
+)
+
+// DependencyAnnotation holds a dependency back, as instructed by a comment on
+// its declaration in pubspec.yaml:
+//
+//	intl: ^0.18.0 # puby:ignore pinned by flutter_localizations
+//	http: ^0.13.0 # puby:max 0.13
+//
+// Any text after the instruction is kept as a note explaining it.
+type DependencyAnnotation struct {
+	// Ignore keeps the dependency at its current constraint.
+	Ignore bool
+
+	// Max is the highest version the dependency may be updated to. Leaving out
+	// components allows every version they would match, e.g. "0.19" allows
+	// 0.19.5 but not 0.20.0. It is empty if there is no limit.
+	Max string
+
+	Note string
+}
+
+// Allows reports whether the annotation allows updating the dependency to the
+// version. A "v" prefix, as is common for git tags, is ignored. Versions that
+// can't be parsed are only held back by puby:ignore.
+func (a DependencyAnnotation) Allows(version string) bool {
+	if a.Ignore {
+		return false
+	}
+	if a.Max == "" {
+		return true
+	}
+
+	parsedVersion, err := semver.Parse(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return true
+	}
+	constraint, err := MaxVersionConstraint(a.Max)
+	if err != nil {
+		return false
+	}
+
+	return constraint.Allows(parsedVersion)
+}
+
+// Reason describes why the annotation holds a dependency back
+func (a DependencyAnnotation) Reason() string {
+	reason := "ignored"
+	if !a.Ignore {
+		reason = "max " + a.Max
+	}
+	if a.Note != "" {
+		reason += ": " + a.Note
+	}
+
+	return reason
+}
+
+// MaxVersionConstraint converts the version of a puby:max annotation into the
+// constraint it stands for: a full version is the highest allowed one, and a
+// partial version allows everything below its next release, e.g. "0.19" stands
+// for "<0.20.0".
+func MaxVersionConstraint(maxVersion string) (semver.Constraint, error) {
+	parts := strings.Split(maxVersion, ".")
+	if len(parts) == 3 {
+		return semver.ParseConstraint("<=" + maxVersion)
+	}
+	if len(parts) > 3 {
+		return semver.Constraint{}, fmt.Errorf("invalid version %q", maxVersion)
+	}
+
+	components := []int{0, 0, 0}
+	for i, part := range parts {
+		component, err := strconv.Atoi(part)
+		if err != nil || component < 0 {
+			return semver.Constraint{}, fmt.Errorf("invalid version %q", maxVersion)
+		}
+		components[i] = component
+	}
+	components[len(parts)-1]++
+
+	return semver.ParseConstraint(fmt.Sprintf("<%d.%d.%d", components[0], components[1], components[2]))
+}
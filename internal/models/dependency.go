@@ -0,0 +1,111 @@
+package models
+
+// DependencySource tells where a dependency is fetched from.
+type DependencySource string
+
+const (
+	HostedSource  DependencySource = "hosted"
+	GitSource     DependencySource = "git"
+	PathSource    DependencySource = "path"
+	SDKSource     DependencySource = "sdk"
+	UnknownSource DependencySource = "unknown"
+)
+
+// Dependency is a single dependency declaration from pubspec.yaml.
+type Dependency struct {
+	Name    string
+	Section DependencySection
+	Source  DependencySource
+
+	// Constraint is the version constraint of a hosted dependency. It is "any"
+	// if the declaration has no version. Path dependencies only have one if it
+	// is given explicitly.
+	Constraint string
+
+	// HostedURL is the package repository a hosted dependency is declared to
+	// come from. It is empty for the default repository.
+	HostedURL string
+
+	// GitURL, GitRef and GitPath describe where a git dependency is fetched
+	// from. The ref and path are empty if they are not specified.
+	GitURL  string
+	GitRef  string
+	GitPath string
+
+	// Path is the directory of a path dependency, relative to the pubspec.yaml
+	// declaring it unless it is absolute.
+	Path string
+}
+
+// ParseDependency interprets the value of a dependency declaration, which is
+// either a version constraint or a map describing the source:
+//
+//	http: ^1.2.0
+//	internal:
+//	  hosted: https://pub.example.com
+//	  version: ^2.0.0
+//	legacy:
+//	  hosted:
+//	    name: legacy
+//	    url: https://pub.example.com
+//	  version: ^1.0.0
+//	forked:
+//	  git:
+//	    url: https://github.com/example/forked.git
+//	    ref: v1.4.0
+//	core:
+//	  path: ../core
+//	  version: ^3.1.0
+func ParseDependency(name string, section DependencySection, value any) Dependency {
+	dependency := Dependency{
+		Name:    name,
+		Section: section,
+		Source:  UnknownSource,
+	}
+
+	switch value := value.(type) {
+	case nil:
+		dependency.Source = HostedSource
+		dependency.Constraint = "any"
+	case string:
+		dependency.Source = HostedSource
+		dependency.Constraint = value
+	case map[string]any:
+		switch {
+		case value["sdk"] != nil:
+			dependency.Source = SDKSource
+		case value["git"] != nil:
+			dependency.Source = GitSource
+
+			switch git := value["git"].(type) {
+			case string:
+				dependency.GitURL = git
+			case map[string]any:
+				dependency.GitURL, _ = git["url"].(string)
+				dependency.GitRef, _ = git["ref"].(string)
+				dependency.GitPath, _ = git["path"].(string)
+			}
+		case value["path"] != nil:
+			dependency.Source = PathSource
+			dependency.Path, _ = value["path"].(string)
+			dependency.Constraint, _ = value["version"].(string)
+		default:
+			dependency.Source = HostedSource
+			dependency.Constraint = "any"
+			if version, ok := value["version"].(string); ok {
+				dependency.Constraint = version
+			}
+
+			switch hosted := value["hosted"].(type) {
+			case string:
+				dependency.HostedURL = hosted
+			case map[string]any:
+				if url, ok := hosted["url"].(string); ok {
+					dependency.HostedURL = url
+				}
+			}
+		}
+	}
+
+	return dependency
+}
@@ -0,0 +1,7 @@
+package models
+
+// GitRefs lists the tag and branch names of a git repository.
+type GitRefs struct {
+	Tags     []string
+	Branches []string
+}
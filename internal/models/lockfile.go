@@ -0,0 +1,49 @@
+package models
+
+import "strings"
+
+// defaultHostedURLs lists the URLs pubspec.lock records for packages from the
+// default package repository
+var defaultHostedURLs = []string{"https://pub.dev", "https://pub.dartlang.org"}
+
+// Lockfile is the content of pubspec.lock, i.e. the versions the dependencies
+// were last resolved to
+type Lockfile struct {
+	Packages map[string]LockedPackage `yaml:"packages"`
+	SDKs     map[string]string        `yaml:"sdks"`
+}
+
+// LockedPackage is a single resolved package from pubspec.lock
+type LockedPackage struct {
+	// Dependency tells how the package is depended on: "direct main",
+	// "direct dev", "direct overridden" or "transitive".
+	Dependency  string `yaml:"dependency"`
+	Description any    `yaml:"description"`
+	Source      string `yaml:"source"`
+	Version     string `yaml:"version"`
+}
+
+// IsTransitive reports whether the package is only depended on indirectly
+func (p LockedPackage) IsTransitive() bool {
+	return p.Dependency == "transitive"
+}
+
+// HostedURL returns the package repository a hosted package was resolved
+// from. It is empty for the default repository and for packages that aren't
+// hosted.
+func (p LockedPackage) HostedURL() string {
+	description, ok := p.Description.(map[string]any)
+	if !ok || p.Source != string(HostedSource) {
+		return ""
+	}
+
+	url, _ := description["url"].(string)
+	url = strings.TrimRight(url, "/")
+	for _, defaultURL := range defaultHostedURLs {
+		if url == defaultURL {
+			return ""
+		}
+	}
+
+	return url
+}
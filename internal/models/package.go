@@ -1,12 +1,20 @@
 package models
 
+import "time"
+
 type PackageWrapper struct {
-	Name          string  `json:"name"`
-	LatestVersion Package `json:"latest"`
+	Name          string    `json:"name"`
+	LatestVersion Package   `json:"latest"`
+	Versions      []Package `json:"versions"`
+
+	// FetchedAt is when the data was fetched from the server. It is set by the
+	// cache and zero when the data comes straight from the server.
+	FetchedAt time.Time `json:"-"`
 }
 
 type Package struct {
 	Version         string         `json:"version"`
 	Dependencies    map[string]any `json:"dependencies"`
 	DevDependencies map[string]any `json:"dev_dependencies"`
+	Retracted       bool           `json:"retracted"`
 }
@@ -1,12 +1,70 @@
 package models
 
 type Pubspec struct {
-	Environment     *PubspecEnvironment `yaml:"environment"`
-	Dependencies    map[string]any      `yaml:"dependencies"`
-	DevDependencies map[string]any      `yaml:"dev_dependencies"`
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+
+	Environment         *PubspecEnvironment `yaml:"environment"`
+	Dependencies        map[string]any      `yaml:"dependencies"`
+	DevDependencies     map[string]any      `yaml:"dev_dependencies"`
+	DependencyOverrides map[string]any      `yaml:"dependency_overrides"`
+
+	// Workspace lists the paths of the packages of a pub workspace, relative to
+	// the directory of this pubspec.yaml. Paths may contain glob patterns.
+	Workspace []string `yaml:"workspace"`
+
+	// Annotations holds the puby comments on dependency declarations, by
+	// package name. They are read from the comments, not the YAML values.
+	Annotations map[string]DependencyAnnotation `yaml:"-"`
 }
 
 type PubspecEnvironment struct {
 	DartSDKVersion    *string `yaml:"sdk"`
 	FlutterSDKVersion *string `yaml:"flutter"`
 }
+
+// DependencySection names a top-level pubspec.yaml section that declares
+// dependencies. The values match the YAML keys.
+type DependencySection string
+
+const (
+	DependenciesSection        DependencySection = "dependencies"
+	DevDependenciesSection     DependencySection = "dev_dependencies"
+	DependencyOverridesSection DependencySection = "dependency_overrides"
+)
+
+// DependencySections lists the dependency sections in the order they are
+// checked and reported.
+var DependencySections = []DependencySection{
+	DependenciesSection,
+	DevDependenciesSection,
+	DependencyOverridesSection,
+}
+
+// DependenciesIn returns the dependencies declared in the given section.
+func (p *Pubspec) DependenciesIn(section DependencySection) map[string]any {
+	switch section {
+	case DependenciesSection:
+		return p.Dependencies
+	case DevDependenciesSection:
+		return p.DevDependencies
+	case DependencyOverridesSection:
+		return p.DependencyOverrides
+	}
+
+	return nil
+}
+
+// UsesFlutter reports whether the package depends on the Flutter SDK, i.e.
+// declares a dependency like `flutter: {sdk: flutter}`.
+func (p *Pubspec) UsesFlutter() bool {
+	for _, section := range DependencySections {
+		for _, value := range p.DependenciesIn(section) {
+			if declaration, ok := value.(map[string]any); ok && declaration["sdk"] == "flutter" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,35 @@
+package models
+
+// ResolutionResult is the outcome of applying dependency updates step by step,
+// keeping only those the project still resolves with.
+type ResolutionResult struct {
+	// EnvironmentUpdate holds the SDK updates that were written. It is nil if
+	// there were none, or if the project doesn't resolve with them.
+	EnvironmentUpdate *EnvironmentUpdate
+
+	Applied []DependencyUpdate
+
+	// EnvironmentConflict is set if the SDK updates were left out because the
+	// project doesn't resolve with them.
+	EnvironmentConflict *EnvironmentConflict
+
+	Conflicts []UpdateConflict
+}
+
+// UpdateConflict is a dependency update that was left out because the project
+// doesn't resolve with it.
+type UpdateConflict struct {
+	Update DependencyUpdate
+
+	// Reason is the output of the resolver explaining the conflict.
+	Reason string
+}
+
+// EnvironmentConflict holds the SDK updates that were left out because the
+// project doesn't resolve with them.
+type EnvironmentConflict struct {
+	Update EnvironmentUpdate
+
+	// Reason is the output of the resolver explaining the conflict.
+	Reason string
+}
@@ -1,8 +1,14 @@
 package models
 
+import "time"
+
 type SDKReleaseWrapper struct {
 	CurrentRelease SDKReleaseHashes `json:"current_release"`
 	Releases       []SDKRelease     `json:"releases"`
+
+	// FetchedAt is when the data was fetched from the server. It is set by the
+	// cache and zero when the data comes straight from the server.
+	FetchedAt time.Time `json:"-"`
 }
 
 type SDKReleaseHashes struct {
@@ -1,17 +1,146 @@
 package models
 
+import (
+	"strings"
+	"time"
+
+	"github.com/sunderee/puby/internal/semver"
+)
+
 type Update struct {
 	EnvironmentUpdate *EnvironmentUpdate
 	DependencyUpdates []DependencyUpdate
+
+	// TransitiveUpdates lists updates of packages that are only depended on
+	// indirectly, according to pubspec.lock. They are not declared in
+	// pubspec.yaml, so they are reported but never written.
+	TransitiveUpdates []DependencyUpdate
+
+	// HeldBack lists the updates that annotations in pubspec.yaml keep from
+	// being proposed. They are reported but never written.
+	HeldBack []HeldBackUpdate
+
+	// Checked lists the declared dependencies that were looked up, whether
+	// they turned out to be up to date or not, so reports can list them all.
+	Checked []Dependency
+
+	// Failures lists the packages that could not be checked. The remaining
+	// updates are still valid when it's not empty.
+	Failures []PackageFailure
+
+	// Unknown lists the packages that were not checked because no cached data
+	// was available while running offline.
+	Unknown []string
+
+	// SDKStatusUnknown is set if the SDKs were not checked because no cached
+	// release data was available while running offline.
+	SDKStatusUnknown bool
+}
+
+// HasUpdates reports whether there is an SDK or dependency update that can be
+// written to pubspec.yaml.
+func (u *Update) HasUpdates() bool {
+	if u == nil {
+		return false
+	}
+
+	if u.EnvironmentUpdate != nil && (u.EnvironmentUpdate.DartSDKVersion != nil || u.EnvironmentUpdate.FlutterSDKVersion != nil) {
+		return true
+	}
+
+	return len(u.DependencyUpdates) > 0
 }
 
 type EnvironmentUpdate struct {
 	DartSDKVersion    *string
 	FlutterSDKVersion *string
+
+	// CachedAt is when the release data was fetched, if it was served from the
+	// cache while running offline. It is zero otherwise.
+	CachedAt time.Time
 }
 
 type DependencyUpdate struct {
 	Name           string
 	CurrentVersion string
 	LatestVersion  string
+
+	// Constraint is the version constraint as declared in pubspec.yaml. It is
+	// empty for transitive and git dependencies.
+	Constraint string
+
+	// ResolvedVersion is the version pubspec.lock resolved the dependency to. It
+	// is empty if there is no lockfile or it doesn't list the dependency.
+	ResolvedVersion string
+
+	// Section is the pubspec.yaml section the dependency was declared in.
+	Section DependencySection
+
+	// HostedURL is the package repository the dependency is hosted on. It is
+	// empty for the default repository.
+	HostedURL string
+
+	// Source is where the dependency comes from. It is empty for hosted
+	// dependencies. For git dependencies, the versions are tag names, and for
+	// path dependencies, the latest version is that of the local package.
+	Source DependencySource
+
+	// CachedAt is when the package data was fetched, if it was served from the
+	// cache while running offline. It is zero otherwise.
+	CachedAt time.Time
+}
+
+// HeldBackUpdate is an update that an annotation in pubspec.yaml holds back.
+// If the annotation still allows an older update, that one is proposed instead.
+type HeldBackUpdate struct {
+	DependencyUpdate
+
+	// Reason describes the annotation, e.g. "max 0.19".
+	Reason string
+}
+
+// PackageFailure describes a package whose latest version could not be fetched.
+type PackageFailure struct {
+	Name string
+
+	// StatusCode is the HTTP status code the server responded with, or zero if
+	// the lookup failed before a response was received.
+	StatusCode int
+
+	Cause error
+}
+
+// UpdateKind classifies a dependency update by the most significant version
+// component that changes.
+type UpdateKind string
+
+const (
+	UpdateKindPatch   UpdateKind = "patch"
+	UpdateKindMinor   UpdateKind = "minor"
+	UpdateKindMajor   UpdateKind = "major"
+	UpdateKindUnknown UpdateKind = "unknown"
+)
+
+// Kind classifies the update as a patch, minor or major bump. As in pub, a new
+// minor version of a 0.x release is breaking, so it counts as a major bump.
+// Updates whose versions cannot be parsed are reported as UpdateKindUnknown. A
+// "v" prefix, as is common for git tags, is ignored.
+func (d DependencyUpdate) Kind() UpdateKind {
+	current, err := semver.Parse(strings.TrimPrefix(d.CurrentVersion, "v"))
+	if err != nil {
+		return UpdateKindUnknown
+	}
+	latest, err := semver.Parse(strings.TrimPrefix(d.LatestVersion, "v"))
+	if err != nil {
+		return UpdateKindUnknown
+	}
+
+	switch {
+	case current.Major != latest.Major, current.Major == 0 && current.Minor != latest.Minor:
+		return UpdateKindMajor
+	case current.Minor != latest.Minor:
+		return UpdateKindMinor
+	}
+
+	return UpdateKindPatch
 }
@@ -0,0 +1,74 @@
+package models
+
+// WorkspaceUpdate is the result of checking every project of a repository or
+// pub workspace in one run.
+type WorkspaceUpdate struct {
+	// RootDir is the directory the projects were discovered in.
+	RootDir string
+
+	Projects []ProjectUpdate
+}
+
+// ProjectUpdate is the result of checking a single project.
+type ProjectUpdate struct {
+	// PubspecPath is the path of the project's pubspec.yaml, relative to the
+	// workspace root.
+	PubspecPath string
+
+	// Update is nil if the project could not be checked, in which case Err
+	// tells why.
+	Update *Update
+	Err    error
+}
+
+// WorkspaceSummary aggregates the results of all projects of a workspace.
+type WorkspaceSummary struct {
+	Projects            int
+	ProjectsWithUpdates int
+	ProjectsWithErrors  int
+
+	// Updates counts the SDK and dependency updates of all projects, and
+	// UpdatesByKind the dependency updates among them by their kind.
+	Updates       int
+	UpdatesByKind map[UpdateKind]int
+
+	// Failures counts the packages that could not be checked, per project.
+	Failures int
+}
+
+// Summary aggregates the results of all projects.
+func (w *WorkspaceUpdate) Summary() WorkspaceSummary {
+	summary := WorkspaceSummary{
+		Projects:      len(w.Projects),
+		UpdatesByKind: make(map[UpdateKind]int),
+	}
+
+	for _, project := range w.Projects {
+		if project.Err != nil {
+			summary.ProjectsWithErrors++
+			continue
+		}
+		if project.Update == nil {
+			continue
+		}
+		if project.Update.HasUpdates() {
+			summary.ProjectsWithUpdates++
+		}
+
+		if env := project.Update.EnvironmentUpdate; env != nil {
+			if env.DartSDKVersion != nil {
+				summary.Updates++
+			}
+			if env.FlutterSDKVersion != nil {
+				summary.Updates++
+			}
+		}
+		for _, dep := range project.Update.DependencyUpdates {
+			summary.Updates++
+			summary.UpdatesByKind[dep.Kind()]++
+		}
+		summary.Failures += len(project.Update.Failures)
+	}
+
+	return summary
+}
@@ -0,0 +1,106 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sunderee/puby/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+const ANNOTATION_PREFIX = "puby:"
+
+// DependencyAnnotations reads the puby comments on the dependency declarations
+// of every section, by package name. A comment may be on any line of a
+// declaration, e.g. after its version:
+//
+//	http:
+//	  version: ^0.13.0 # puby:max 0.13
+//
+// Comments that don't start with "puby:" are left alone, but unknown or
+// malformed puby instructions are reported as errors.
+func (d *PubspecDocument) DependencyAnnotations() (map[string]models.DependencyAnnotation, error) {
+	annotations := make(map[string]models.DependencyAnnotation)
+	for _, section := range models.DependencySections {
+		dependencies := d.Lookup(string(section))
+		if dependencies == nil || dependencies.Kind != yaml.MappingNode {
+			continue
+		}
+
+		for i := 0; i+1 < len(dependencies.Content); i += 2 {
+			name := dependencies.Content[i].Value
+			annotation := annotations[name]
+			annotated := false
+
+			for _, comment := range lineComments(dependencies.Content[i], dependencies.Content[i+1]) {
+				parsed, ok, err := parseAnnotation(comment, annotation)
+				if err != nil {
+					return nil, fmt.Errorf("invalid annotation on %s in %s: %v", name, section, err)
+				}
+				if ok {
+					annotation = parsed
+					annotated = true
+				}
+			}
+
+			if annotated {
+				annotations[name] = annotation
+			}
+		}
+	}
+
+	return annotations, nil
+}
+
+// lineComments returns the comments at the end of the lines of the nodes and
+// everything nested within them
+func lineComments(nodes ...*yaml.Node) []string {
+	var comments []string
+	for _, node := range nodes {
+		if node.LineComment != "" {
+			comments = append(comments, node.LineComment)
+		}
+		comments = append(comments, lineComments(node.Content...)...)
+	}
+
+	return comments
+}
+
+// parseAnnotation adds the instruction of a comment to the annotation. It
+// reports false if the comment holds no puby instruction.
+func parseAnnotation(comment string, annotation models.DependencyAnnotation) (models.DependencyAnnotation, bool, error) {
+	text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(comment), "#"))
+	instruction, ok := strings.CutPrefix(text, ANNOTATION_PREFIX)
+	if !ok {
+		return annotation, false, nil
+	}
+
+	fields := strings.Fields(instruction)
+	if len(fields) == 0 {
+		return annotation, false, fmt.Errorf("missing instruction after %q", ANNOTATION_PREFIX)
+	}
+
+	var note []string
+	switch fields[0] {
+	case "ignore":
+		annotation.Ignore = true
+		note = fields[1:]
+	case "max":
+		if len(fields) < 2 {
+			return annotation, false, fmt.Errorf("missing version after %q", ANNOTATION_PREFIX+"max")
+		}
+		if _, err := models.MaxVersionConstraint(fields[1]); err != nil {
+			return annotation, false, err
+		}
+		annotation.Max = fields[1]
+		note = fields[2:]
+	default:
+		return annotation, false, fmt.Errorf("unknown instruction %q (expected ignore or max)", ANNOTATION_PREFIX+fields[0])
+	}
+
+	if len(note) > 0 {
+		annotation.Note = strings.Join(note, " ")
+	}
+
+	return annotation, true, nil
+}
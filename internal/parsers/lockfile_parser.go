@@ -0,0 +1,33 @@
+package parsers
+
+import (
+	"os"
+
+	"github.com/sunderee/puby/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+type LockfileParser struct {
+	LockfileFilePath string
+}
+
+func NewLockfileParser(lockfileFilePath string) *LockfileParser {
+	return &LockfileParser{
+		LockfileFilePath: lockfileFilePath,
+	}
+}
+
+// Open the pubspec.lock file and parse it into a Lockfile struct
+func (p *LockfileParser) Parse() (*models.Lockfile, error) {
+	yamlFile, err := os.ReadFile(p.LockfileFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var lockfile models.Lockfile
+	if err := yaml.Unmarshal(yamlFile, &lockfile); err != nil {
+		return nil, err
+	}
+
+	return &lockfile, nil
+}
@@ -0,0 +1,10 @@
+package parsers
+
+import (
+	"github.com/sunderee/puby/internal/models"
+)
+
+// LockfileParserInterface defines the interface for pubspec.lock file parsing
+type LockfileParserInterface interface {
+	Parse() (*models.Lockfile, error)
+}
@@ -0,0 +1,51 @@
+package parsers
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockfileParser_Parse(t *testing.T) {
+	lockfile, err := NewLockfileParser(filepath.Join("testdata", "pubspec.lock")).Parse()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"dart": ">=3.3.0 <4.0.0", "flutter": ">=3.19.0"}, lockfile.SDKs)
+	require.Len(t, lockfile.Packages, 8)
+
+	tests := []struct {
+		name       string
+		version    string
+		source     string
+		transitive bool
+		hostedURL  string
+	}{
+		{name: "async", version: "2.11.0", source: "hosted", transitive: true},
+		{name: "collection", version: "1.18.0", source: "hosted", transitive: true},
+		{name: "flutter", version: "0.0.0", source: "sdk"},
+		{name: "forked", version: "1.2.0", source: "git"},
+		{name: "http", version: "1.2.1", source: "hosted"},
+		{name: "internal_pkg", version: "2.1.0", source: "hosted", hostedURL: "https://pub.example.com"},
+		{name: "lints", version: "3.0.0", source: "hosted"},
+		{name: "models", version: "1.4.0", source: "path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locked, ok := lockfile.Packages[tt.name]
+			require.True(t, ok)
+
+			assert.Equal(t, tt.version, locked.Version)
+			assert.Equal(t, tt.source, locked.Source)
+			assert.Equal(t, tt.transitive, locked.IsTransitive())
+			assert.Equal(t, tt.hostedURL, locked.HostedURL())
+		})
+	}
+}
+
+func TestLockfileParser_Parse_MissingFile(t *testing.T) {
+	_, err := NewLockfileParser(filepath.Join(t.TempDir(), "pubspec.lock")).Parse()
+	assert.Error(t, err)
+}
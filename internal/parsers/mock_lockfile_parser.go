@@ -0,0 +1,15 @@
+package parsers
+
+import (
+	"github.com/sunderee/puby/internal/models"
+)
+
+// MockLockfileParser is a mock implementation of LockfileParserInterface
+type MockLockfileParser struct {
+	ParseFunc func() (*models.Lockfile, error)
+}
+
+// Parse implements the LockfileParserInterface
+func (m *MockLockfileParser) Parse() (*models.Lockfile, error) {
+	return m.ParseFunc()
+}
@@ -0,0 +1,161 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PubspecDocument is the YAML node tree of a pubspec.yaml together with its
+// source, so values can be located and edited without reformatting the rest of
+// the file
+type PubspecDocument struct {
+	Content []byte
+	Root    *yaml.Node
+
+	// lineOffsets holds the byte offset at which each line starts
+	lineOffsets []int
+}
+
+// ParsePubspecDocument parses the content of a pubspec.yaml into its node tree
+func ParsePubspecDocument(content []byte) (*PubspecDocument, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+
+	lineOffsets := []int{0}
+	for i, b := range content {
+		if b == '\n' {
+			lineOffsets = append(lineOffsets, i+1)
+		}
+	}
+
+	return &PubspecDocument{
+		Content:     content,
+		Root:        &root,
+		lineOffsets: lineOffsets,
+	}, nil
+}
+
+// Lookup follows the path of mapping keys from the top of the document and
+// returns the value node at its end, or nil if any key along the way is
+// missing.
+func (d *PubspecDocument) Lookup(path ...string) *yaml.Node {
+	node := d.Root
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+
+	for _, key := range path {
+		node = mappingValue(node, key)
+		if node == nil {
+			return nil
+		}
+	}
+
+	return node
+}
+
+// mappingValue returns the value of the key in a mapping node, or nil if the
+// node is not a mapping or doesn't contain the key
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// Offset converts the line and column of a node, which count characters from
+// one, into a byte offset in the content
+func (d *PubspecDocument) Offset(node *yaml.Node) (int, error) {
+	if node.Line < 1 || node.Line > len(d.lineOffsets) {
+		return 0, fmt.Errorf("line %d is outside of the document", node.Line)
+	}
+
+	offset := d.lineOffsets[node.Line-1]
+	for column := 1; column < node.Column; column++ {
+		if offset >= len(d.Content) || d.Content[offset] == '\n' {
+			return 0, fmt.Errorf("column %d is outside of line %d", node.Column, node.Line)
+		}
+		_, width := utf8.DecodeRune(d.Content[offset:])
+		offset += width
+	}
+
+	return offset, nil
+}
+
+// ScalarSpan returns the byte range a scalar node occupies in the content,
+// including its quotes. Block scalars and plain scalars spanning several lines
+// are not supported.
+func (d *PubspecDocument) ScalarSpan(node *yaml.Node) (int, int, error) {
+	if node.Kind != yaml.ScalarNode {
+		return 0, 0, fmt.Errorf("value on line %d is not a scalar", node.Line)
+	}
+
+	start, err := d.Offset(node)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch node.Style {
+	case yaml.DoubleQuotedStyle:
+		for i := start + 1; i < len(d.Content); i++ {
+			switch d.Content[i] {
+			case '\\':
+				i++
+			case '"':
+				return start, i + 1, nil
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := start + 1; i < len(d.Content); i++ {
+			if d.Content[i] != '\'' {
+				continue
+			}
+			if i+1 < len(d.Content) && d.Content[i+1] == '\'' {
+				i++
+				continue
+			}
+			return start, i + 1, nil
+		}
+	case 0:
+		end := start + len(node.Value)
+		if end <= len(d.Content) && string(d.Content[start:end]) == node.Value {
+			return start, end, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("value on line %d has an unsupported format", node.Line)
+}
+
+// FormatScalar formats a value in the given scalar style, so a replaced value
+// keeps the quoting of the original. Plain values that YAML would read
+// differently are single-quoted instead.
+func FormatScalar(style yaml.Style, value string) string {
+	switch style {
+	case yaml.DoubleQuotedStyle:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+	case yaml.SingleQuotedStyle:
+		return `'` + strings.ReplaceAll(value, `'`, `''`) + `'`
+	}
+
+	if value == "" || strings.ContainsAny(value[:1], "!&*>|%@`'\"#{}[],-?:") ||
+		strings.Contains(value, ": ") || strings.Contains(value, " #") {
+		return FormatScalar(yaml.SingleQuotedStyle, value)
+	}
+
+	return value
+}
@@ -29,5 +29,15 @@ func (p *PubspecParser) Parse() (*models.Pubspec, error) {
 		return nil, err
 	}
 
+	// Read the puby comments on the dependencies
+	document, err := ParsePubspecDocument(yamlFile)
+	if err != nil {
+		return nil, err
+	}
+	pubspec.Annotations, err = document.DependencyAnnotations()
+	if err != nil {
+		return nil, err
+	}
+
 	return &pubspec, nil
 }
@@ -0,0 +1,182 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a parsed pub version constraint. A nil bound means the range
+// is unbounded on that side, so a constraint without bounds allows any
+// version.
+type Constraint struct {
+	Min        *Version
+	IncludeMin bool
+	Max        *Version
+	IncludeMax bool
+}
+
+// constraintOperators lists the supported operators, longest first so that
+// ">=" is not mistaken for ">".
+var constraintOperators = []string{">=", "<=", ">", "<", "^", "~"}
+
+// ParseConstraint parses pub constraint syntax: "any", an exact version,
+// "^1.2.3", comparisons such as ">=1.0.0" and combinations like
+// ">=1.0.0 <2.0.0", with or without spaces between them. Quotes around the
+// constraint are ignored.
+func ParseConstraint(input string) (Constraint, error) {
+	text := strings.Trim(strings.TrimSpace(input), `"'`)
+	if text == "" {
+		return Constraint{}, fmt.Errorf("empty version constraint")
+	}
+	if text == "any" {
+		return Constraint{}, nil
+	}
+
+	result := Constraint{}
+	for _, term := range constraintTerms(text) {
+		version, err := Parse(term.version)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid version constraint %q: %v", input, err)
+		}
+
+		result = result.intersect(constraintFor(term.operator, version))
+	}
+
+	return result, nil
+}
+
+// constraintTerm is a single operator and the version it applies to
+type constraintTerm struct {
+	operator string
+	version  string
+}
+
+// constraintTerms splits a constraint into its terms. A version ends at the
+// next space or operator, so ">=1.0.0<2.0.0" is read like ">=1.0.0 <2.0.0".
+func constraintTerms(text string) []constraintTerm {
+	var terms []constraintTerm
+	for text = strings.TrimSpace(text); text != ""; text = strings.TrimSpace(text) {
+		operator := ""
+		for _, candidate := range constraintOperators {
+			if strings.HasPrefix(text, candidate) {
+				operator = candidate
+				break
+			}
+		}
+		text = strings.TrimSpace(text[len(operator):])
+
+		end := strings.IndexAny(text, " <>^~")
+		if end < 0 {
+			end = len(text)
+		}
+		terms = append(terms, constraintTerm{operator: operator, version: text[:end]})
+		text = text[end:]
+	}
+
+	return terms
+}
+
+// constraintFor builds the constraint a single operator and version describe.
+func constraintFor(operator string, version Version) Constraint {
+	switch operator {
+	case ">=":
+		return Constraint{Min: &version, IncludeMin: true}
+	case ">":
+		return Constraint{Min: &version}
+	case "<=":
+		return Constraint{Max: &version, IncludeMax: true}
+	case "<":
+		return Constraint{Max: &version}
+	case "^":
+		max := version.NextBreaking()
+		return Constraint{Min: &version, IncludeMin: true, Max: &max}
+	case "~":
+		max := version.NextMinor()
+		return Constraint{Min: &version, IncludeMin: true, Max: &max}
+	}
+
+	return Constraint{Min: &version, IncludeMin: true, Max: &version, IncludeMax: true}
+}
+
+// intersect returns the range allowed by both c and other.
+func (c Constraint) intersect(other Constraint) Constraint {
+	result := c
+
+	if other.Min != nil {
+		if result.Min == nil || other.Min.GreaterThan(*result.Min) {
+			result.Min, result.IncludeMin = other.Min, other.IncludeMin
+		} else if other.Min.Equal(*result.Min) {
+			result.IncludeMin = result.IncludeMin && other.IncludeMin
+		}
+	}
+
+	if other.Max != nil {
+		if result.Max == nil || other.Max.LessThan(*result.Max) {
+			result.Max, result.IncludeMax = other.Max, other.IncludeMax
+		} else if other.Max.Equal(*result.Max) {
+			result.IncludeMax = result.IncludeMax && other.IncludeMax
+		}
+	}
+
+	return result
+}
+
+// IsAny reports whether the constraint allows every version.
+func (c Constraint) IsAny() bool {
+	return c.Min == nil && c.Max == nil
+}
+
+// Allows reports whether the version satisfies the constraint. As in pub, an
+// exclusive upper bound such as "<2.0.0" also excludes pre-releases of that
+// version unless the bound is itself a pre-release.
+func (c Constraint) Allows(version Version) bool {
+	if c.Min != nil {
+		result := version.Compare(*c.Min)
+		if result < 0 || (result == 0 && !c.IncludeMin) {
+			return false
+		}
+	}
+
+	if c.Max != nil {
+		result := version.Compare(*c.Max)
+		if result > 0 || (result == 0 && !c.IncludeMax) {
+			return false
+		}
+
+		if !c.IncludeMax && version.IsPreRelease() && !c.Max.IsPreRelease() &&
+			version.Major == c.Max.Major && version.Minor == c.Max.Minor && version.Patch == c.Max.Patch {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String formats the constraint in canonical pub syntax.
+func (c Constraint) String() string {
+	if c.IsAny() {
+		return "any"
+	}
+
+	if c.Min != nil && c.Max != nil && c.IncludeMin && c.IncludeMax && c.Min.Equal(*c.Max) {
+		return c.Min.String()
+	}
+
+	var parts []string
+	if c.Min != nil {
+		if c.IncludeMin {
+			parts = append(parts, ">="+c.Min.String())
+		} else {
+			parts = append(parts, ">"+c.Min.String())
+		}
+	}
+	if c.Max != nil {
+		if c.IncludeMax {
+			parts = append(parts, "<="+c.Max.String())
+		} else {
+			parts = append(parts, "<"+c.Max.String())
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
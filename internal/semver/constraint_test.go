@@ -0,0 +1,79 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{input: "any", expected: "any"},
+		{input: "1.2.3", expected: "1.2.3"},
+		{input: "^1.2.3", expected: ">=1.2.3 <2.0.0"},
+		{input: "^0.13.3", expected: ">=0.13.3 <0.14.0"},
+		{input: "~1.8.0", expected: ">=1.8.0 <1.9.0"},
+		{input: ">=1.0.0 <2.0.0", expected: ">=1.0.0 <2.0.0"},
+		{input: "'>=2.12.0 <3.0.0'", expected: ">=2.12.0 <3.0.0"},
+		{input: ">= 1.0.0  < 2.0.0", expected: ">=1.0.0 <2.0.0"},
+		{input: ">1.0.0 <=1.5.0", expected: ">1.0.0 <=1.5.0"},
+		{input: "<2.0.0", expected: "<2.0.0"},
+		{input: ">=1.0.0 >=1.2.0 <3.0.0 <2.0.0", expected: ">=1.2.0 <2.0.0"},
+		{input: ">=1.0.0<2.0.0", expected: ">=1.0.0 <2.0.0"},
+		{input: ">=3.0.0-0<4.0.0", expected: ">=3.0.0-0 <4.0.0"},
+		{input: ">=", expectError: true},
+		{input: "", expectError: true},
+		{input: "^1.2", expectError: true},
+		{input: ">=abc", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseConstraint(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result.String())
+		})
+	}
+}
+
+func TestConstraint_Allows(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{"any", "99.0.0", true},
+		{"^1.2.3", "1.2.3", true},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "1.2.2", false},
+		{"^0.13.3", "0.13.5", true},
+		{"^0.13.3", "0.14.0", false},
+		{"1.2.3", "1.2.3", true},
+		{"1.2.3", "1.2.4", false},
+		{">=1.0.0 <2.0.0", "1.5.0", true},
+		{">=1.0.0 <2.0.0", "2.0.0", false},
+		{">=1.0.0 <2.0.0", "2.0.0-dev", false},
+		{">=1.0.0 <2.0.0-dev", "2.0.0-rc", false},
+		{">=1.0.0 <2.0.0-dev.2", "2.0.0-dev.1", true},
+		{">1.0.0", "1.0.0", false},
+		{"<=1.5.0", "1.5.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" allows "+tt.version, func(t *testing.T) {
+			constraint, err := ParseConstraint(tt.constraint)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, constraint.Allows(MustParse(tt.version)))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RewriteConstraint moves a constraint up to the version while keeping the way
+// it is written: "^1.2.0" stays a caret constraint, "~1.2.0" a tilde one and an
+// exact version stays pinned. Ranges get the version as their lower bound, and
+// their upper bound is raised to the next breaking version if it would exclude
+// the version; an upper bound that is already higher is kept. "any" is
+// returned unchanged.
+func RewriteConstraint(constraint string, version Version) (string, error) {
+	text := strings.TrimSpace(constraint)
+	parsed, err := ParseConstraint(text)
+	if err != nil {
+		return "", err
+	}
+	if parsed.IsAny() {
+		return constraint, nil
+	}
+
+	if terms := constraintTerms(strings.Trim(text, `"'`)); len(terms) == 1 {
+		switch terms[0].operator {
+		case "^", "~":
+			return terms[0].operator + version.String(), nil
+		case "":
+			return version.String(), nil
+		}
+	}
+
+	rewritten := Constraint{Max: parsed.Max, IncludeMax: parsed.IncludeMax}
+	if parsed.Min != nil {
+		rewritten.Min, rewritten.IncludeMin = &version, true
+	}
+	if parsed.Max != nil && !rewritten.allowsUpperBound(version) {
+		next := version.NextBreaking()
+		rewritten.Max, rewritten.IncludeMax = &next, false
+	}
+
+	result := rewritten.String()
+	if result == "any" {
+		return "", fmt.Errorf("cannot rewrite version constraint %q", constraint)
+	}
+
+	return result, nil
+}
+
+// allowsUpperBound reports whether the version is below the upper bound of the
+// constraint, ignoring its lower bound
+func (c Constraint) allowsUpperBound(version Version) bool {
+	return Constraint{Max: c.Max, IncludeMax: c.IncludeMax}.Allows(version)
+}
@@ -0,0 +1,47 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteConstraint(t *testing.T) {
+	tests := []struct {
+		constraint  string
+		version     string
+		expected    string
+		expectError bool
+	}{
+		{constraint: "^1.2.0", version: "1.3.0", expected: "^1.3.0"},
+		{constraint: "^1.2.0", version: "2.0.0", expected: "^2.0.0"},
+		{constraint: "~1.2.0", version: "1.2.5", expected: "~1.2.5"},
+		{constraint: "1.2.0", version: "1.3.0", expected: "1.3.0"},
+		{constraint: ">=1.2.0 <2.0.0", version: "1.5.0", expected: ">=1.5.0 <2.0.0"},
+		{constraint: ">=1.2.0 <2.0.0", version: "2.1.0", expected: ">=2.1.0 <3.0.0"},
+		{constraint: ">=0.13.0 <0.14.0", version: "0.14.2", expected: ">=0.14.2 <0.15.0"},
+		{constraint: ">=1.2.0 <=2.0.0", version: "1.5.0", expected: ">=1.5.0 <=2.0.0"},
+		{constraint: ">=1.2.0 <=2.0.0", version: "2.1.0", expected: ">=2.1.0 <3.0.0"},
+		{constraint: ">=1.2.0 <4.0.0", version: "2.0.0", expected: ">=2.0.0 <4.0.0"},
+		{constraint: ">1.2.0 <2.0.0", version: "1.5.0", expected: ">=1.5.0 <2.0.0"},
+		{constraint: ">=2.12.0", version: "3.5.0", expected: ">=3.5.0"},
+		{constraint: "<2.0.0", version: "2.1.0", expected: "<3.0.0"},
+		{constraint: ">=3.0.0-0 <4.0.0", version: "3.5.0", expected: ">=3.5.0 <4.0.0"},
+		{constraint: ">=1.2.0<2.0.0", version: "1.5.0", expected: ">=1.5.0 <2.0.0"},
+		{constraint: "any", version: "1.0.0", expected: "any"},
+		{constraint: "latest", version: "1.0.0", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" to "+tt.version, func(t *testing.T) {
+			result, err := RewriteConstraint(tt.constraint, MustParse(tt.version))
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
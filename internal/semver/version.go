@@ -0,0 +1,197 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionPattern matches versions as understood by pub: three numeric
+// components, an optional pre-release suffix and optional build metadata.
+var versionPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// Version is a parsed Dart/pub package version, e.g. 1.2.3-dev.1+build.4.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease []string
+	Build      []string
+}
+
+// Parse parses a version string such as "1.2.3", "2.0.0-beta.1" or
+// "1.0.0+hotfix.2".
+func Parse(input string) (Version, error) {
+	matches := versionPattern.FindStringSubmatch(strings.TrimSpace(input))
+	if matches == nil {
+		return Version{}, fmt.Errorf("invalid version %q", input)
+	}
+
+	var version Version
+	var err error
+	if version.Major, err = strconv.Atoi(matches[1]); err != nil {
+		return Version{}, fmt.Errorf("invalid major version in %q: %v", input, err)
+	}
+	if version.Minor, err = strconv.Atoi(matches[2]); err != nil {
+		return Version{}, fmt.Errorf("invalid minor version in %q: %v", input, err)
+	}
+	if version.Patch, err = strconv.Atoi(matches[3]); err != nil {
+		return Version{}, fmt.Errorf("invalid patch version in %q: %v", input, err)
+	}
+
+	if matches[4] != "" {
+		version.PreRelease = strings.Split(matches[4], ".")
+	}
+	if matches[5] != "" {
+		version.Build = strings.Split(matches[5], ".")
+	}
+
+	return version, nil
+}
+
+// MustParse is like Parse but panics if the version cannot be parsed. It is
+// meant for versions known at compile time.
+func MustParse(input string) Version {
+	version, err := Parse(input)
+	if err != nil {
+		panic(err)
+	}
+
+	return version
+}
+
+// IsPreRelease reports whether the version has a pre-release suffix.
+func (v Version) IsPreRelease() bool {
+	return len(v.PreRelease) > 0
+}
+
+// Compare returns -1, 0 or 1 depending on whether v sorts before, equal to or
+// after other. Pre-releases sort before the release they precede and, like
+// in pub, versions with build metadata sort after the same version without.
+func (v Version) Compare(other Version) int {
+	if result := compareInts(v.Major, other.Major); result != 0 {
+		return result
+	}
+	if result := compareInts(v.Minor, other.Minor); result != 0 {
+		return result
+	}
+	if result := compareInts(v.Patch, other.Patch); result != 0 {
+		return result
+	}
+
+	// A pre-release comes before its release
+	if v.IsPreRelease() != other.IsPreRelease() {
+		if v.IsPreRelease() {
+			return -1
+		}
+		return 1
+	}
+	if result := compareIdentifiers(v.PreRelease, other.PreRelease); result != 0 {
+		return result
+	}
+
+	// A build comes after no build
+	if (len(v.Build) > 0) != (len(other.Build) > 0) {
+		if len(v.Build) > 0 {
+			return 1
+		}
+		return -1
+	}
+
+	return compareIdentifiers(v.Build, other.Build)
+}
+
+// LessThan reports whether v sorts before other.
+func (v Version) LessThan(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// GreaterThan reports whether v sorts after other.
+func (v Version) GreaterThan(other Version) bool {
+	return v.Compare(other) > 0
+}
+
+// Equal reports whether v and other are the same version.
+func (v Version) Equal(other Version) bool {
+	return v.Compare(other) == 0
+}
+
+// NextMajor returns the first version of the next major release.
+func (v Version) NextMajor() Version {
+	return Version{Major: v.Major + 1}
+}
+
+// NextMinor returns the first version of the next minor release.
+func (v Version) NextMinor() Version {
+	return Version{Major: v.Major, Minor: v.Minor + 1}
+}
+
+// NextPatch returns the next patch release.
+func (v Version) NextPatch() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// NextBreaking returns the first version that is not backwards compatible
+// with v according to pub's rules: the major version for 1.0.0 and later,
+// the minor version for 0.x and the patch version for 0.0.x.
+func (v Version) NextBreaking() Version {
+	if v.Major > 0 {
+		return v.NextMajor()
+	}
+	if v.Minor > 0 {
+		return v.NextMinor()
+	}
+
+	return v.NextPatch()
+}
+
+// String formats the version the way it was written.
+func (v Version) String() string {
+	result := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.IsPreRelease() {
+		result += "-" + strings.Join(v.PreRelease, ".")
+	}
+	if len(v.Build) > 0 {
+		result += "+" + strings.Join(v.Build, ".")
+	}
+
+	return result
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// compareIdentifiers compares dot-separated pre-release or build identifiers.
+// Numeric identifiers compare numerically and sort before alphanumeric ones.
+func compareIdentifiers(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		aNumber, aErr := strconv.Atoi(a[i])
+		bNumber, bErr := strconv.Atoi(b[i])
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if result := compareInts(aNumber, bNumber); result != 0 {
+				return result
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if result := strings.Compare(a[i], b[i]); result != 0 {
+				return result
+			}
+		}
+	}
+
+	return compareInts(len(a), len(b))
+}
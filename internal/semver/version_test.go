@@ -0,0 +1,118 @@
+package semver
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Version
+		expectError bool
+	}{
+		{
+			input:    "1.2.3",
+			expected: Version{Major: 1, Minor: 2, Patch: 3},
+		},
+		{
+			input:    "1.10.0",
+			expected: Version{Major: 1, Minor: 10, Patch: 0},
+		},
+		{
+			input:    "2.0.0-beta.1",
+			expected: Version{Major: 2, PreRelease: []string{"beta", "1"}},
+		},
+		{
+			input:    "1.0.0+hotfix.2",
+			expected: Version{Major: 1, Build: []string{"hotfix", "2"}},
+		},
+		{
+			input:    "3.6.0-216.1.beta+build",
+			expected: Version{Major: 3, Minor: 6, PreRelease: []string{"216", "1", "beta"}, Build: []string{"build"}},
+		},
+		{input: "", expectError: true},
+		{input: "1.2", expectError: true},
+		{input: "^1.2.3", expectError: true},
+		{input: "v1.2.3", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := Parse(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.input, result.String())
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.10.0", "1.9.0", 1},
+		{"1.9.0", "1.10.0", -1},
+		{"1.2.3", "1.2.3", 0},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-dev", "1.0.0", -1},
+		{"1.0.0-alpha.2", "1.0.0-alpha.10", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0+1", "1.0.0", 1},
+		{"1.0.0+1", "1.0.0+2", -1},
+		{"1.0.0-dev+1", "1.0.0", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MustParse(tt.a).Compare(MustParse(tt.b)))
+			assert.Equal(t, -tt.expected, MustParse(tt.b).Compare(MustParse(tt.a)))
+		})
+	}
+}
+
+func TestVersion_Sort(t *testing.T) {
+	inputs := []string{"1.10.0", "1.0.0", "1.0.0+1", "1.9.0", "1.0.0-beta", "0.9.0"}
+	versions := make([]Version, len(inputs))
+	for i, input := range inputs {
+		versions[i] = MustParse(input)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].LessThan(versions[j])
+	})
+
+	var result []string
+	for _, version := range versions {
+		result = append(result, version.String())
+	}
+	assert.Equal(t, []string{"0.9.0", "1.0.0-beta", "1.0.0", "1.0.0+1", "1.9.0", "1.10.0"}, result)
+}
+
+func TestVersion_NextBreaking(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.2.3", "2.0.0"},
+		{"0.13.3", "0.14.0"},
+		{"0.0.3", "0.0.4"},
+		{"2.0.0-dev", "3.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MustParse(tt.input).NextBreaking().String())
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/models"
+	"github.com/sunderee/puby/internal/parsers"
+	"github.com/sunderee/puby/internal/semver"
+)
+
+// alignedSections lists the sections whose constraints are aligned. Overrides
+// are left out, as they differ between projects on purpose.
+var alignedSections = []models.DependencySection{
+	models.DependenciesSection,
+	models.DevDependenciesSection,
+}
+
+// AlignService finds hosted dependencies that the projects of a workspace
+// require at different minimum versions, and proposes a version for all of them
+type AlignService struct {
+	RootDir      string
+	PubspecPaths []string
+
+	// If this is set, dependencies are aligned on their latest version rather
+	// than on the highest version one of the projects already requires.
+	APIService APIServiceInterface
+
+	// PackageRules holds the rules of the project configuration, by package
+	// name. Ignored packages are not aligned, and no package is aligned on a
+	// version its rule doesn't allow.
+	PackageRules map[string]config.PackageRule
+}
+
+// NewAlignService creates a new instance of AlignService
+func NewAlignService(rootDir string, pubspecPaths []string) *AlignService {
+	return &AlignService{
+		RootDir:      rootDir,
+		PubspecPaths: pubspecPaths,
+	}
+}
+
+// FindAlignments returns the dependencies whose minimum versions differ
+// between projects, sorted by name. Constraints without a minimum version,
+// like "any", are left alone, and so are dependencies that a puby:ignore
+// annotation or the package's rule ignores. A project whose puby:max
+// annotation doesn't allow the target version is aligned on the highest
+// version it allows instead.
+func (s *AlignService) FindAlignments() ([]models.Alignment, error) {
+	return s.FindAlignmentsWithContext(context.Background())
+}
+
+// FindAlignmentsWithContext returns the dependencies to align like
+// FindAlignments, aborting the latest version lookups once the context is
+// cancelled
+func (s *AlignService) FindAlignmentsWithContext(ctx context.Context) ([]models.Alignment, error) {
+	alignmentsByKey := make(map[string]*models.Alignment)
+	annotationsByKey := make(map[string][]models.DependencyAnnotation)
+	for _, pubspecPath := range s.PubspecPaths {
+		pubspec, err := parsers.NewPubspecParser(pubspecPath).Parse()
+		if err != nil {
+			return nil, err
+		}
+
+		relativePath, err := filepath.Rel(s.RootDir, pubspecPath)
+		if err != nil {
+			relativePath = pubspecPath
+		}
+
+		for _, section := range alignedSections {
+			for dependencyName, dependencyValue := range pubspec.DependenciesIn(section) {
+				dependency := models.ParseDependency(dependencyName, section, dependencyValue)
+				annotation := pubspec.Annotations[dependencyName]
+				if dependency.Source != models.HostedSource || annotation.Ignore || s.PackageRules[dependencyName].Ignore {
+					continue
+				}
+
+				constraint, err := semver.ParseConstraint(dependency.Constraint)
+				if err != nil || constraint.Min == nil {
+					continue
+				}
+
+				key := packageKey(dependency)
+				if alignmentsByKey[key] == nil {
+					alignmentsByKey[key] = &models.Alignment{Name: dependency.Name, HostedURL: dependency.HostedURL}
+				}
+				alignmentsByKey[key].Usages = append(alignmentsByKey[key].Usages, models.AlignmentUsage{
+					PubspecPath: filepath.ToSlash(relativePath),
+					Section:     section,
+					Constraint:  dependency.Constraint,
+					Version:     constraint.Min.String(),
+				})
+				annotationsByKey[key] = append(annotationsByKey[key], annotation)
+			}
+		}
+	}
+
+	var alignments []models.Alignment
+	for key, alignment := range alignmentsByKey {
+		highest, misaligned := highestVersion(alignment.Usages)
+		if !misaligned {
+			continue
+		}
+
+		// The versions to align on are the ones already required, and the
+		// latest one if it's newer
+		candidates := make([]semver.Version, 0, len(alignment.Usages)+1)
+		for _, usage := range alignment.Usages {
+			candidates = append(candidates, semver.MustParse(usage.Version))
+		}
+		if s.APIService != nil {
+			latest, err := s.latestVersion(ctx, alignment.Name, alignment.HostedURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up %s: %v", alignment.Name, err)
+			}
+			if latest.GreaterThan(highest) {
+				candidates = append(candidates, latest)
+			}
+		}
+
+		rule := s.PackageRules[alignment.Name]
+		target, ok := highestAllowedVersion(candidates, rule.Allows)
+		if !ok {
+			continue
+		}
+		alignment.TargetVersion = target.String()
+
+		// Hold back the projects that already require a newer version than the
+		// rule allows, and those whose annotation doesn't allow the target
+		for i, annotation := range annotationsByKey[key] {
+			usage := &alignment.Usages[i]
+			if semver.MustParse(usage.Version).GreaterThan(target) {
+				usage.TargetVersion = usage.Version
+				usage.Reason = rule.Reason()
+				continue
+			}
+			if annotation.Allows(alignment.TargetVersion) {
+				continue
+			}
+
+			usage.TargetVersion = usage.Version
+			usage.Reason = annotation.Reason()
+			capped, ok := highestAllowedVersion(candidates, func(version semver.Version) bool {
+				return !version.GreaterThan(target) && rule.Allows(version) && annotation.Allows(version.String())
+			})
+			if ok && capped.GreaterThan(semver.MustParse(usage.Version)) {
+				usage.TargetVersion = capped.String()
+			}
+		}
+
+		sort.SliceStable(alignment.Usages, func(i, j int) bool {
+			return alignment.Usages[i].PubspecPath < alignment.Usages[j].PubspecPath
+		})
+		alignments = append(alignments, *alignment)
+	}
+
+	sort.Slice(alignments, func(i, j int) bool {
+		if alignments[i].Name != alignments[j].Name {
+			return alignments[i].Name < alignments[j].Name
+		}
+		return alignments[i].HostedURL < alignments[j].HostedURL
+	})
+
+	return alignments, nil
+}
+
+// highestVersion returns the highest minimum version of the usages, and
+// whether the usages disagree on it
+func highestVersion(usages []models.AlignmentUsage) (semver.Version, bool) {
+	var highest semver.Version
+	misaligned := false
+	for i, usage := range usages {
+		version := semver.MustParse(usage.Version)
+		if i > 0 && !version.Equal(highest) {
+			misaligned = true
+		}
+		if i == 0 || version.GreaterThan(highest) {
+			highest = version
+		}
+	}
+
+	return highest, misaligned
+}
+
+// highestAllowedVersion returns the highest of the versions that allows
+// accepts, and whether there is one
+func highestAllowedVersion(versions []semver.Version, allows func(semver.Version) bool) (semver.Version, bool) {
+	var highest semver.Version
+	found := false
+	for _, version := range versions {
+		if allows(version) && (!found || version.GreaterThan(highest)) {
+			highest = version
+			found = true
+		}
+	}
+
+	return highest, found
+}
+
+// latestVersion looks up the latest version of a package
+func (s *AlignService) latestVersion(ctx context.Context, name, hostedURL string) (semver.Version, error) {
+	var packageData *models.PackageWrapper
+	var err error
+	if hostedURL == "" {
+		packageData, err = s.APIService.GetPackage(ctx, name)
+	} else {
+		packageData, err = s.APIService.GetHostedPackage(ctx, hostedURL, name)
+	}
+	if err != nil {
+		return semver.Version{}, err
+	}
+
+	return semver.Parse(packageData.LatestVersion.Version)
+}
+
+// AlignmentUpdates converts the alignments into the updates to write to each
+// project, keyed by the path of its pubspec.yaml relative to the workspace
+// root. Usages already at or above their target version are left out, so a
+// constraint is never moved down.
+func AlignmentUpdates(alignments []models.Alignment) map[string]*models.Update {
+	updates := make(map[string]*models.Update)
+	for _, alignment := range alignments {
+		for _, usage := range alignment.Usages {
+			targetVersion := alignment.TargetVersion
+			if usage.TargetVersion != "" {
+				targetVersion = usage.TargetVersion
+			}
+			if !semver.MustParse(targetVersion).GreaterThan(semver.MustParse(usage.Version)) {
+				continue
+			}
+
+			if updates[usage.PubspecPath] == nil {
+				updates[usage.PubspecPath] = &models.Update{}
+			}
+			updates[usage.PubspecPath].DependencyUpdates = append(updates[usage.PubspecPath].DependencyUpdates, models.DependencyUpdate{
+				Name:           alignment.Name,
+				CurrentVersion: usage.Version,
+				LatestVersion:  targetVersion,
+				Constraint:     usage.Constraint,
+				Section:        usage.Section,
+				HostedURL:      alignment.HostedURL,
+			})
+		}
+	}
+
+	return updates
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestAlignService_FindAlignments(t *testing.T) {
+	rootDir := t.TempDir()
+	writeFiles(t, rootDir, map[string]string{
+		"apps/mobile/pubspec.yaml": `name: mobile
+dependencies:
+  http: ^0.13.0
+  path: ^1.8.0
+  internal_pkg:
+    hosted: https://pub.example.com
+    version: ^2.0.0
+dev_dependencies:
+  lints: any
+dependency_overrides:
+  meta: 1.9.0
+`,
+		"packages/core/pubspec.yaml": `name: core
+dependencies:
+  http: ">=1.1.0 <2.0.0"
+  path: ^1.8.0
+  internal_pkg:
+    hosted: https://pub.example.com
+    version: ^2.1.0
+  local:
+    path: ../local
+dev_dependencies:
+  lints: ^4.0.0
+dependency_overrides:
+  meta: 1.11.0
+`,
+	})
+	pubspecPaths := []string{
+		filepath.Join(rootDir, "apps", "mobile", "pubspec.yaml"),
+		filepath.Join(rootDir, "packages", "core", "pubspec.yaml"),
+	}
+
+	t.Run("Highest required version", func(t *testing.T) {
+		alignments, err := NewAlignService(rootDir, pubspecPaths).FindAlignments()
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Alignment{
+			{
+				Name:          "http",
+				TargetVersion: "1.1.0",
+				Usages: []models.AlignmentUsage{
+					{PubspecPath: "apps/mobile/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^0.13.0", Version: "0.13.0"},
+					{PubspecPath: "packages/core/pubspec.yaml", Section: models.DependenciesSection, Constraint: ">=1.1.0 <2.0.0", Version: "1.1.0"},
+				},
+			},
+			{
+				Name:          "internal_pkg",
+				HostedURL:     "https://pub.example.com",
+				TargetVersion: "2.1.0",
+				Usages: []models.AlignmentUsage{
+					{PubspecPath: "apps/mobile/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^2.0.0", Version: "2.0.0"},
+					{PubspecPath: "packages/core/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^2.1.0", Version: "2.1.0"},
+				},
+			},
+		}, alignments)
+	})
+
+	t.Run("Latest version", func(t *testing.T) {
+		alignService := NewAlignService(rootDir, pubspecPaths)
+		alignService.APIService = &MockAPIService{
+			GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+				return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: "1.2.2"}}, nil
+			},
+			GetHostedPackageFunc: func(hostedURL, packageName string) (*models.PackageWrapper, error) {
+				// Older than what a project already requires, so it is not used
+				return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: "2.0.5"}}, nil
+			},
+		}
+
+		alignments, err := alignService.FindAlignments()
+
+		require.NoError(t, err)
+		require.Len(t, alignments, 2)
+		assert.Equal(t, "1.2.2", alignments[0].TargetVersion)
+		assert.Equal(t, "2.1.0", alignments[1].TargetVersion)
+	})
+}
+
+func TestAlignService_FindAlignments_HeldBack(t *testing.T) {
+	rootDir := t.TempDir()
+	writeFiles(t, rootDir, map[string]string{
+		"apps/mobile/pubspec.yaml": `name: mobile
+dependencies:
+  http: ^0.13.0 # puby:max 1.0 waiting for the new client
+  path: ^1.8.0
+dev_dependencies:
+  lints: ^3.0.0 # puby:ignore
+`,
+		"apps/web/pubspec.yaml": `name: web
+dependencies:
+  http: ^1.0.0
+`,
+		"packages/core/pubspec.yaml": `name: core
+dependencies:
+  http: ^1.1.0
+  path: ^1.9.0
+dev_dependencies:
+  lints: ^4.0.0
+`,
+	})
+	pubspecPaths := []string{
+		filepath.Join(rootDir, "apps", "mobile", "pubspec.yaml"),
+		filepath.Join(rootDir, "apps", "web", "pubspec.yaml"),
+		filepath.Join(rootDir, "packages", "core", "pubspec.yaml"),
+	}
+
+	alignService := NewAlignService(rootDir, pubspecPaths)
+	alignService.APIService = &MockAPIService{
+		GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+			return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: "2.0.0"}}, nil
+		},
+	}
+	alignService.PackageRules = map[string]config.PackageRule{
+		"http": {MaxMajor: intPtr(1)},
+		"path": {Ignore: true},
+	}
+
+	alignments, err := alignService.FindAlignments()
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.Alignment{
+		{
+			Name:          "http",
+			TargetVersion: "1.1.0",
+			Usages: []models.AlignmentUsage{
+				{PubspecPath: "apps/mobile/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^0.13.0", Version: "0.13.0", TargetVersion: "1.0.0", Reason: "max 1.0: waiting for the new client"},
+				{PubspecPath: "apps/web/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^1.0.0", Version: "1.0.0"},
+				{PubspecPath: "packages/core/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^1.1.0", Version: "1.1.0"},
+			},
+		},
+	}, alignments)
+
+	updates := AlignmentUpdates(alignments)
+	assert.Equal(t, "1.0.0", updates["apps/mobile/pubspec.yaml"].DependencyUpdates[0].LatestVersion)
+	assert.Equal(t, "1.1.0", updates["apps/web/pubspec.yaml"].DependencyUpdates[0].LatestVersion)
+	assert.NotContains(t, updates, "packages/core/pubspec.yaml")
+}
+
+func TestAlignService_FindAlignments_AboveRule(t *testing.T) {
+	rootDir := t.TempDir()
+	writeFiles(t, rootDir, map[string]string{
+		"apps/mobile/pubspec.yaml":   "name: mobile\ndependencies:\n  http: ^1.0.0\n",
+		"apps/web/pubspec.yaml":      "name: web\ndependencies:\n  http: ^0.13.0\n",
+		"packages/core/pubspec.yaml": "name: core\ndependencies:\n  http: ^2.0.0\n",
+	})
+	pubspecPaths := []string{
+		filepath.Join(rootDir, "apps", "mobile", "pubspec.yaml"),
+		filepath.Join(rootDir, "apps", "web", "pubspec.yaml"),
+		filepath.Join(rootDir, "packages", "core", "pubspec.yaml"),
+	}
+
+	alignService := NewAlignService(rootDir, pubspecPaths)
+	alignService.PackageRules = map[string]config.PackageRule{"http": {MaxMajor: intPtr(1)}}
+
+	alignments, err := alignService.FindAlignments()
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.Alignment{
+		{
+			Name:          "http",
+			TargetVersion: "1.0.0",
+			Usages: []models.AlignmentUsage{
+				{PubspecPath: "apps/mobile/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^1.0.0", Version: "1.0.0"},
+				{PubspecPath: "apps/web/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^0.13.0", Version: "0.13.0"},
+				{PubspecPath: "packages/core/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^2.0.0", Version: "2.0.0", TargetVersion: "2.0.0", Reason: "max-major 1"},
+			},
+		},
+	}, alignments)
+
+	// The project above the rule is never moved down
+	updates := AlignmentUpdates(alignments)
+	assert.Equal(t, map[string]*models.Update{
+		"apps/web/pubspec.yaml": {
+			DependencyUpdates: []models.DependencyUpdate{
+				{Name: "http", CurrentVersion: "0.13.0", LatestVersion: "1.0.0", Constraint: "^0.13.0", Section: models.DependenciesSection},
+			},
+		},
+	}, updates)
+}
+
+func TestAlignmentUpdates(t *testing.T) {
+	updates := AlignmentUpdates([]models.Alignment{
+		{
+			Name:          "http",
+			TargetVersion: "1.1.0",
+			Usages: []models.AlignmentUsage{
+				{PubspecPath: "apps/mobile/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^0.13.0", Version: "0.13.0"},
+				{PubspecPath: "packages/core/pubspec.yaml", Section: models.DependenciesSection, Constraint: "^1.1.0", Version: "1.1.0"},
+			},
+		},
+		{
+			Name:          "lints",
+			TargetVersion: "4.0.0",
+			Usages: []models.AlignmentUsage{
+				{PubspecPath: "apps/mobile/pubspec.yaml", Section: models.DevDependenciesSection, Constraint: "^3.0.0", Version: "3.0.0"},
+				{PubspecPath: "packages/core/pubspec.yaml", Section: models.DevDependenciesSection, Constraint: "^4.0.0", Version: "4.0.0"},
+			},
+		},
+	})
+
+	assert.Equal(t, map[string]*models.Update{
+		"apps/mobile/pubspec.yaml": {
+			DependencyUpdates: []models.DependencyUpdate{
+				{Name: "http", CurrentVersion: "0.13.0", LatestVersion: "1.1.0", Constraint: "^0.13.0", Section: models.DependenciesSection},
+				{Name: "lints", CurrentVersion: "3.0.0", LatestVersion: "4.0.0", Constraint: "^3.0.0", Section: models.DevDependenciesSection},
+			},
+		},
+	}, updates)
+}
@@ -1,92 +1,186 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 
+	"github.com/sunderee/puby/internal/config"
 	"github.com/sunderee/puby/internal/models"
 )
 
 const (
 	DEFAULT_SDK_RELEASE_URL = "https://storage.googleapis.com/flutter_infra_release/releases/releases_macos.json"
 	DEFAULT_PACKAGE_URL     = "https://pub.dev/api/packages/%s"
+	DEFAULT_HOSTED_URL      = "https://pub.dev"
 	HTTP_METHOD             = "GET"
+	PUB_HOSTED_URL_ENV      = "PUB_HOSTED_URL"
+	PUB_API_ACCEPT_HEADER   = "application/vnd.pub.v2+json"
 )
 
+// ErrNotModified is returned by conditional requests when the server reports
+// that the data identified by the given ETag is still current
+var ErrNotModified = errors.New("not modified")
+
+// HTTPStatusError is returned when a server responds with an error status code
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("server returned status code %d", e.StatusCode)
+}
+
 type APIService struct {
 	Client        *http.Client
 	SDKReleaseURL string
 	PackageURL    string
+
+	// Tokens provides the bearer tokens sent to private package repositories
+	Tokens *config.PubTokens
 }
 
+// NewAPIService creates a new instance of APIService. Packages are looked up on
+// pub.dev unless the PUB_HOSTED_URL environment variable names another default
+// package repository.
 func NewAPIService() *APIService {
+	packageURL := DEFAULT_PACKAGE_URL
+	if hostedURL := os.Getenv(PUB_HOSTED_URL_ENV); hostedURL != "" {
+		packageURL = strings.ReplaceAll(packageAPIURL(hostedURL), "%", "%%") + "%s"
+	}
+
 	return &APIService{
 		Client:        &http.Client{},
 		SDKReleaseURL: DEFAULT_SDK_RELEASE_URL,
-		PackageURL:    DEFAULT_PACKAGE_URL,
+		PackageURL:    packageURL,
+		Tokens:        &config.PubTokens{},
 	}
 }
 
-// GetSDKRelease fetches the latest SDK release from the Flutter repository
-func (s *APIService) GetSDKRelease() (*models.SDKReleaseWrapper, error) {
-	request, err := http.NewRequest(HTTP_METHOD, s.SDKReleaseURL, nil)
-	if err != nil {
-		return nil, err
+// DefaultHostedURL returns the URL of the default package repository: the one
+// named by the PUB_HOSTED_URL environment variable, or pub.dev
+func DefaultHostedURL() string {
+	if hostedURL := os.Getenv(PUB_HOSTED_URL_ENV); hostedURL != "" {
+		return strings.TrimRight(hostedURL, "/")
 	}
 
-	response, err := s.Client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
+	return DEFAULT_HOSTED_URL
+}
 
-	body, err := io.ReadAll(response.Body)
+// GetSDKRelease fetches the latest SDK release from the Flutter repository
+func (s *APIService) GetSDKRelease(ctx context.Context) (*models.SDKReleaseWrapper, error) {
+	sdkRelease, _, err := s.GetSDKReleaseIfNoneMatch(ctx, "")
+	return sdkRelease, err
+}
+
+// GetSDKReleaseIfNoneMatch fetches the latest SDK release unless it still
+// matches the given ETag, in which case ErrNotModified is returned. It also
+// returns the ETag of the fetched data, if the server sent one.
+func (s *APIService) GetSDKReleaseIfNoneMatch(ctx context.Context, etag string) (*models.SDKReleaseWrapper, string, error) {
+	body, responseETag, err := s.get(ctx, s.SDKReleaseURL, "", etag)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var sdkRelease models.SDKReleaseWrapper
 	err = json.Unmarshal(body, &sdkRelease)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return &sdkRelease, nil
+	return &sdkRelease, responseETag, nil
+}
+
+// GetPackage fetches the latest package data from the default package repository
+func (s *APIService) GetPackage(ctx context.Context, packageName string) (*models.PackageWrapper, error) {
+	packageWrapper, _, err := s.GetHostedPackageIfNoneMatch(ctx, "", packageName, "")
+	return packageWrapper, err
+}
+
+// GetHostedPackage fetches the latest package data from the package repository
+// at the given URL, or from the default one if the URL is empty
+func (s *APIService) GetHostedPackage(ctx context.Context, hostedURL, packageName string) (*models.PackageWrapper, error) {
+	packageWrapper, _, err := s.GetHostedPackageIfNoneMatch(ctx, hostedURL, packageName, "")
+	return packageWrapper, err
 }
 
-// GetPackage fetches the latest package data from the pub.dev packages repository
-func (s *APIService) GetPackage(packageName string) (*models.PackageWrapper, error) {
+// GetHostedPackageIfNoneMatch fetches the package data unless it still matches
+// the given ETag, in which case ErrNotModified is returned. It also returns the
+// ETag of the fetched data, if the server sent one. An empty hosted URL stands
+// for the default package repository.
+func (s *APIService) GetHostedPackageIfNoneMatch(ctx context.Context, hostedURL, packageName, etag string) (*models.PackageWrapper, string, error) {
 	if packageName == "" {
-		return nil, fmt.Errorf("package name cannot be empty")
+		return nil, "", fmt.Errorf("package name cannot be empty")
 	}
 
-	request, err := http.NewRequest(HTTP_METHOD, fmt.Sprintf(s.PackageURL, packageName), nil)
+	requestURL := fmt.Sprintf(s.PackageURL, packageName)
+	if hostedURL != "" {
+		requestURL = packageAPIURL(hostedURL) + url.PathEscape(packageName)
+	}
+
+	body, responseETag, err := s.get(ctx, requestURL, PUB_API_ACCEPT_HEADER, etag)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var packageWrapper models.PackageWrapper
+	err = json.Unmarshal(body, &packageWrapper)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	return &packageWrapper, responseETag, nil
+}
+
+// packageAPIURL returns the URL prefix of the package API of a repository
+func packageAPIURL(hostedURL string) string {
+	return strings.TrimRight(hostedURL, "/") + "/api/packages/"
+}
+
+// get performs a GET request, conditional on the ETag if one is given, and
+// returns the response body together with the response ETag. The Accept
+// header is only sent if it's not empty. Cancelling the context aborts the
+// request.
+func (s *APIService) get(ctx context.Context, requestURL, accept, etag string) ([]byte, string, error) {
+	request, err := http.NewRequestWithContext(ctx, HTTP_METHOD, requestURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+	if s.Tokens != nil {
+		if token := s.Tokens.TokenFor(requestURL); token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
 	}
 
 	response, err := s.Client.Do(request)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusNotModified {
+		return nil, "", ErrNotModified
+	}
 	if response.StatusCode >= 400 {
-		return nil, fmt.Errorf("server returned status code %d", response.StatusCode)
+		return nil, "", &HTTPStatusError{StatusCode: response.StatusCode}
 	}
 
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, err
-	}
-
-	var packageWrapper models.PackageWrapper
-	err = json.Unmarshal(body, &packageWrapper)
-	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return &packageWrapper, nil
+	return body, response.Header.Get("ETag"), nil
 }
@@ -1,11 +1,21 @@
 package services
 
 import (
+	"context"
 	"github.com/sunderee/puby/internal/models"
 )
 
 // APIServiceInterface defines the interface for API service operations
 type APIServiceInterface interface {
-	GetSDKRelease() (*models.SDKReleaseWrapper, error)
-	GetPackage(packageName string) (*models.PackageWrapper, error)
+	GetSDKRelease(ctx context.Context) (*models.SDKReleaseWrapper, error)
+	GetPackage(ctx context.Context, packageName string) (*models.PackageWrapper, error)
+	GetHostedPackage(ctx context.Context, hostedURL, packageName string) (*models.PackageWrapper, error)
+}
+
+// RevalidatingAPIServiceInterface is implemented by API services that can make
+// conditional requests to revalidate previously fetched data using its ETag
+type RevalidatingAPIServiceInterface interface {
+	APIServiceInterface
+	GetSDKReleaseIfNoneMatch(ctx context.Context, etag string) (*models.SDKReleaseWrapper, string, error)
+	GetHostedPackageIfNoneMatch(ctx context.Context, hostedURL, packageName, etag string) (*models.PackageWrapper, string, error)
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/sunderee/puby/internal/config"
 	"github.com/sunderee/puby/internal/models"
 )
 
@@ -130,7 +132,7 @@ func TestGetSDKRelease(t *testing.T) {
 			apiService := NewAPIService()
 			apiService.SDKReleaseURL = server.URL
 
-			result, err := apiService.GetSDKRelease()
+			result, err := apiService.GetSDKRelease(context.Background())
 
 			// Check expectations
 			if tc.expectError {
@@ -242,7 +244,7 @@ func TestGetPackage(t *testing.T) {
 			apiService := NewAPIService()
 			apiService.PackageURL = server.URL + "/%s"
 
-			result, err := apiService.GetPackage(tc.packageName)
+			result, err := apiService.GetPackage(context.Background(), tc.packageName)
 
 			// Check expectations
 			if tc.expectError {
@@ -256,6 +258,103 @@ func TestGetPackage(t *testing.T) {
 	}
 }
 
+func TestGetPackage_HTTPStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	apiService := NewAPIService()
+	apiService.PackageURL = server.URL + "/%s"
+
+	_, err := apiService.GetPackage(context.Background(), "private_package")
+
+	var statusError *HTTPStatusError
+	assert.ErrorAs(t, err, &statusError)
+	assert.Equal(t, http.StatusNotFound, statusError.StatusCode)
+}
+
+func TestGetHostedPackage(t *testing.T) {
+	var requestedPath, authorization, accept string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requestedPath = req.URL.Path
+		authorization = req.Header.Get("Authorization")
+		accept = req.Header.Get("Accept")
+		fmt.Fprintln(rw, `{"name": "internal_pkg", "latest": {"version": "2.1.0"}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("PUB_TEST_TOKEN", "secret-from-env")
+
+	testCases := []struct {
+		name                  string
+		hostedURL             string
+		tokens                *config.PubTokens
+		expectedPath          string
+		expectedAuthorization string
+	}{
+		{
+			name:         "without token",
+			hostedURL:    server.URL,
+			tokens:       &config.PubTokens{},
+			expectedPath: "/api/packages/internal_pkg",
+		},
+		{
+			name:      "with token",
+			hostedURL: server.URL + "/",
+			tokens: &config.PubTokens{Hosted: []config.PubToken{
+				{URL: "https://pub.example.com", Token: "other"},
+				{URL: server.URL, Token: "secret"},
+			}},
+			expectedPath:          "/api/packages/internal_pkg",
+			expectedAuthorization: "Bearer secret",
+		},
+		{
+			name:      "with token from environment",
+			hostedURL: server.URL + "/org/repo",
+			tokens: &config.PubTokens{Hosted: []config.PubToken{
+				{URL: server.URL, Token: "too-broad"},
+				{URL: server.URL + "/org/repo/", Env: "PUB_TEST_TOKEN"},
+			}},
+			expectedPath:          "/org/repo/api/packages/internal_pkg",
+			expectedAuthorization: "Bearer secret-from-env",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			apiService := NewAPIService()
+			apiService.Tokens = tc.tokens
+
+			result, err := apiService.GetHostedPackage(context.Background(), tc.hostedURL, "internal_pkg")
+
+			assert.NoError(t, err)
+			assert.Equal(t, "2.1.0", result.LatestVersion.Version)
+			assert.Equal(t, tc.expectedPath, requestedPath)
+			assert.Equal(t, tc.expectedAuthorization, authorization)
+			assert.Equal(t, PUB_API_ACCEPT_HEADER, accept)
+		})
+	}
+}
+
+func TestNewAPIService_PubHostedURL(t *testing.T) {
+	var requestedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requestedPath = req.URL.Path
+		fmt.Fprintln(rw, `{"name": "http", "latest": {"version": "1.2.0"}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv(PUB_HOSTED_URL_ENV, server.URL+"/mirror/")
+
+	apiService := NewAPIService()
+	result, err := apiService.GetPackage(context.Background(), "http")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.0", result.LatestVersion.Version)
+	assert.Equal(t, "/mirror/api/packages/http", requestedPath)
+}
+
 func TestAPIService_RequestCreationError(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -268,7 +367,7 @@ func TestAPIService_RequestCreationError(t *testing.T) {
 				s.SDKReleaseURL = "\\invalid-url\\"
 			},
 			testFunc: func(s *APIService) error {
-				_, err := s.GetSDKRelease()
+				_, err := s.GetSDKRelease(context.Background())
 				return err
 			},
 		},
@@ -278,7 +377,7 @@ func TestAPIService_RequestCreationError(t *testing.T) {
 				s.PackageURL = "\\invalid-url\\%s"
 			},
 			testFunc: func(s *APIService) error {
-				_, err := s.GetPackage("test")
+				_, err := s.GetPackage(context.Background(), "test")
 				return err
 			},
 		},
@@ -319,8 +418,32 @@ func TestAPIService_ResponseBodyReadError(t *testing.T) {
 	apiService := NewAPIService()
 	apiService.SDKReleaseURL = server.URL
 
-	_, err := apiService.GetSDKRelease()
+	_, err := apiService.GetSDKRelease(context.Background())
 
 	// Verify an error was returned
 	assert.Error(t, err)
 }
+
+func TestAPIService_CancelledRequest(t *testing.T) {
+	// Setup a test HTTP server that only responds once the request is cancelled
+	requested := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(requested)
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	apiService := NewAPIService()
+	apiService.SDKReleaseURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-requested
+		cancel()
+	}()
+
+	_, err := apiService.GetSDKRelease(ctx)
+
+	// Verify the running request was aborted with the context error
+	assert.ErrorIs(t, err, context.Canceled)
+}
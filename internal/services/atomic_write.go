@@ -0,0 +1,35 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomically replaces the file at the path with the content, so that
+// readers and crashes only ever see the old or the new content. The content is
+// written to a temporary file in the same directory, flushed to disk and then
+// renamed over the original.
+func writeFileAtomically(path string, content []byte, mode os.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(content); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tempFile.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), path)
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+const (
+	DEFAULT_CACHE_TTL     = time.Hour
+	CACHE_DIRECTORY_NAME  = "puby"
+	SDK_RELEASE_CACHE_KEY = "sdk-release"
+	PACKAGE_CACHE_PREFIX  = "package:"
+)
+
+// ErrNotCached is returned in offline mode when the requested data is not in the cache
+var ErrNotCached = errors.New("no cached data available")
+
+// CachedAPIService wraps an API service with an on-disk cache. Cached data is
+// served as is until it is older than the TTL. After that it is revalidated
+// using its ETag if the wrapped service supports conditional requests, and
+// refetched otherwise. The returned data carries the time it was fetched.
+type CachedAPIService struct {
+	APIService APIServiceInterface
+	CacheDir   string
+	TTL        time.Duration
+
+	// DefaultHostedURL is the package repository the wrapped service looks up
+	// packages without a hosted URL on. It is part of their cache key, so
+	// switching to another repository doesn't serve data cached for this one.
+	DefaultHostedURL string
+
+	// If this flag is set, the wrapped service is never called. Cached data is
+	// served regardless of its age and missing data results in ErrNotCached.
+	Offline bool
+
+	// Now returns the current time. It can be replaced in tests.
+	Now func() time.Time
+}
+
+// cacheEntry is the on-disk representation of a cached API response
+type cacheEntry struct {
+	Key       string          `json:"key"`
+	ETag      string          `json:"etag,omitempty"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewCachedAPIService creates a new instance of CachedAPIService
+func NewCachedAPIService(apiService APIServiceInterface, cacheDir string, ttl time.Duration) *CachedAPIService {
+	return &CachedAPIService{
+		APIService:       apiService,
+		CacheDir:         cacheDir,
+		TTL:              ttl,
+		DefaultHostedURL: DefaultHostedURL(),
+		Now:              time.Now,
+	}
+}
+
+// DefaultCacheDir returns the directory API responses are cached in, inside
+// the user's cache directory
+func DefaultCacheDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(userCacheDir, CACHE_DIRECTORY_NAME), nil
+}
+
+// CleanCache removes all cached API responses
+func CleanCache(cacheDir string) error {
+	return os.RemoveAll(cacheDir)
+}
+
+// GetSDKRelease implements the APIServiceInterface
+func (s *CachedAPIService) GetSDKRelease(ctx context.Context) (*models.SDKReleaseWrapper, error) {
+	fetch := func() (*models.SDKReleaseWrapper, error) {
+		return s.APIService.GetSDKRelease(ctx)
+	}
+
+	var revalidate func(etag string) (*models.SDKReleaseWrapper, string, error)
+	if revalidatingService, ok := s.APIService.(RevalidatingAPIServiceInterface); ok {
+		revalidate = func(etag string) (*models.SDKReleaseWrapper, string, error) {
+			return revalidatingService.GetSDKReleaseIfNoneMatch(ctx, etag)
+		}
+	}
+
+	sdkRelease, fetchedAt, err := getCached(s, SDK_RELEASE_CACHE_KEY, fetch, revalidate)
+	if err != nil {
+		return nil, err
+	}
+
+	sdkRelease.FetchedAt = fetchedAt
+	return sdkRelease, nil
+}
+
+// GetPackage implements the APIServiceInterface
+func (s *CachedAPIService) GetPackage(ctx context.Context, packageName string) (*models.PackageWrapper, error) {
+	return s.GetHostedPackage(ctx, "", packageName)
+}
+
+// GetHostedPackage implements the APIServiceInterface
+func (s *CachedAPIService) GetHostedPackage(ctx context.Context, hostedURL, packageName string) (*models.PackageWrapper, error) {
+	fetch := func() (*models.PackageWrapper, error) {
+		if hostedURL == "" {
+			return s.APIService.GetPackage(ctx, packageName)
+		}
+		return s.APIService.GetHostedPackage(ctx, hostedURL, packageName)
+	}
+
+	var revalidate func(etag string) (*models.PackageWrapper, string, error)
+	if revalidatingService, ok := s.APIService.(RevalidatingAPIServiceInterface); ok {
+		revalidate = func(etag string) (*models.PackageWrapper, string, error) {
+			return revalidatingService.GetHostedPackageIfNoneMatch(ctx, hostedURL, packageName, etag)
+		}
+	}
+
+	repositoryURL := hostedURL
+	if repositoryURL == "" {
+		repositoryURL = s.DefaultHostedURL
+	}
+	key := PACKAGE_CACHE_PREFIX + repositoryURL + " " + packageName
+
+	packageWrapper, fetchedAt, err := getCached(s, key, fetch, revalidate)
+	if err != nil {
+		return nil, err
+	}
+
+	packageWrapper.FetchedAt = fetchedAt
+	return packageWrapper, nil
+}
+
+// getCached returns the cached data for the key while it is fresh. Otherwise it
+// revalidates or refetches the data and updates the cache. The revalidate
+// function is optional; without it, stale data is always refetched. Failing to
+// write the cache does not fail the lookup. Along with the data, it returns the
+// time the data was fetched from the server.
+func getCached[T any](
+	s *CachedAPIService,
+	key string,
+	fetch func() (*T, error),
+	revalidate func(etag string) (*T, string, error),
+) (*T, time.Time, error) {
+	entry, cached := s.readEntry(key)
+
+	// Serve fresh data straight from the cache, and any cached data when offline
+	if cached && (s.Offline || s.Now().Sub(entry.FetchedAt) < s.TTL) {
+		var data T
+		if err := json.Unmarshal(entry.Data, &data); err == nil {
+			return &data, entry.FetchedAt, nil
+		}
+	}
+	if s.Offline {
+		return nil, time.Time{}, ErrNotCached
+	}
+
+	var data *T
+	var etag string
+	var err error
+	switch {
+	case revalidate != nil && cached && entry.ETag != "":
+		data, etag, err = revalidate(entry.ETag)
+		if errors.Is(err, ErrNotModified) {
+			var cachedData T
+			if err := json.Unmarshal(entry.Data, &cachedData); err == nil {
+				entry.FetchedAt = s.Now()
+				_ = s.writeEntry(entry)
+				return &cachedData, entry.FetchedAt, nil
+			}
+
+			// The cached copy is unreadable, so fetch it again in full
+			data, etag, err = revalidate("")
+		}
+	case revalidate != nil:
+		data, etag, err = revalidate("")
+	default:
+		data, err = fetch()
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	fetchedAt := s.Now()
+	if encoded, err := json.Marshal(data); err == nil {
+		_ = s.writeEntry(&cacheEntry{
+			Key:       key,
+			ETag:      etag,
+			FetchedAt: fetchedAt,
+			Data:      encoded,
+		})
+	}
+
+	return data, fetchedAt, nil
+}
+
+// entryPath returns the path of the file the entry for the key is stored in
+func (s *CachedAPIService) entryPath(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(s.CacheDir, hex.EncodeToString(hash[:])+".json")
+}
+
+// readEntry reads the cache entry for the key. Missing or unreadable entries
+// are reported as not cached.
+func (s *CachedAPIService) readEntry(key string) (*cacheEntry, bool) {
+	content, err := os.ReadFile(s.entryPath(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil || entry.Key != key {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// writeEntry stores the cache entry, replacing any previous one atomically so
+// concurrent lookups never see a partially written file
+func (s *CachedAPIService) writeEntry(entry *cacheEntry) error {
+	if err := os.MkdirAll(s.CacheDir, 0755); err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(s.entryPath(entry.Key), content, 0600)
+}
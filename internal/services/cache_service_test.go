@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestCachedAPIService_GetPackage(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("fresh data is served from the cache", func(t *testing.T) {
+		calls := 0
+		service := NewCachedAPIService(&MockAPIService{
+			GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+				calls++
+				return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: "1.2.0"}}, nil
+			},
+		}, t.TempDir(), time.Hour)
+		service.Now = func() time.Time { return now }
+
+		first, err := service.GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+
+		service.Now = func() time.Time { return now.Add(59 * time.Minute) }
+		second, err := service.GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, first, second)
+		assert.Equal(t, "1.2.0", second.LatestVersion.Version)
+	})
+
+	t.Run("stale data is refetched", func(t *testing.T) {
+		calls := 0
+		service := NewCachedAPIService(&MockAPIService{
+			GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+				calls++
+				return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: fmt.Sprintf("1.%d.0", calls)}}, nil
+			},
+		}, t.TempDir(), time.Hour)
+		service.Now = func() time.Time { return now }
+
+		_, err := service.GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+
+		service.Now = func() time.Time { return now.Add(2 * time.Hour) }
+		result, err := service.GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, "1.2.0", result.LatestVersion.Version)
+	})
+
+	t.Run("packages are cached separately", func(t *testing.T) {
+		service := NewCachedAPIService(&MockAPIService{
+			GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+				return &models.PackageWrapper{Name: packageName}, nil
+			},
+		}, t.TempDir(), time.Hour)
+
+		httpPackage, err := service.GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+		pathPackage, err := service.GetPackage(context.Background(), "path")
+		assert.NoError(t, err)
+
+		assert.Equal(t, "http", httpPackage.Name)
+		assert.Equal(t, "path", pathPackage.Name)
+	})
+
+	t.Run("default repositories are cached separately", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		newService := func(defaultHostedURL, version string) *CachedAPIService {
+			service := NewCachedAPIService(&MockAPIService{
+				GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+					return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: version}}, nil
+				},
+			}, cacheDir, time.Hour)
+			service.DefaultHostedURL = defaultHostedURL
+			service.Now = func() time.Time { return now }
+			return service
+		}
+
+		fromPubDev, err := newService(DEFAULT_HOSTED_URL, "1.2.0").GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+		fromMirror, err := newService("https://pub.example.com", "1.1.0").GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+
+		assert.Equal(t, "1.2.0", fromPubDev.LatestVersion.Version)
+		assert.Equal(t, "1.1.0", fromMirror.LatestVersion.Version)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		calls := 0
+		service := NewCachedAPIService(&MockAPIService{
+			GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+				calls++
+				if calls == 1 {
+					return nil, errors.New("connection reset by peer")
+				}
+				return &models.PackageWrapper{Name: packageName}, nil
+			},
+		}, t.TempDir(), time.Hour)
+
+		_, err := service.GetPackage(context.Background(), "http")
+		assert.Error(t, err)
+
+		result, err := service.GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+		assert.Equal(t, "http", result.Name)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("stale data is revalidated with its ETag", func(t *testing.T) {
+		var fullResponses, notModifiedResponses int
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("If-None-Match") == `"v1"` {
+				notModifiedResponses++
+				rw.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			fullResponses++
+			rw.Header().Set("ETag", `"v1"`)
+			fmt.Fprintln(rw, `{"name": "http", "latest": {"version": "1.2.0"}}`)
+		}))
+		defer server.Close()
+
+		apiService := NewAPIService()
+		apiService.PackageURL = server.URL + "/%s"
+
+		service := NewCachedAPIService(apiService, t.TempDir(), time.Hour)
+		service.Now = func() time.Time { return now }
+
+		_, err := service.GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+
+		service.Now = func() time.Time { return now.Add(2 * time.Hour) }
+		result, err := service.GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+		assert.Equal(t, "1.2.0", result.LatestVersion.Version)
+
+		// The revalidation refreshed the entry, so it is fresh again
+		service.Now = func() time.Time { return now.Add(150 * time.Minute) }
+		_, err = service.GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, fullResponses)
+		assert.Equal(t, 1, notModifiedResponses)
+	})
+}
+
+func TestCachedAPIService_Offline(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cacheDir := t.TempDir()
+
+	calls := 0
+	apiService := &MockAPIService{
+		GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+			calls++
+			return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: "1.2.0"}}, nil
+		},
+	}
+
+	// Populate the cache while online
+	online := NewCachedAPIService(apiService, cacheDir, time.Hour)
+	online.Now = func() time.Time { return now }
+	_, err := online.GetPackage(context.Background(), "http")
+	assert.NoError(t, err)
+
+	offline := NewCachedAPIService(apiService, cacheDir, time.Hour)
+	offline.Offline = true
+	offline.Now = func() time.Time { return now.Add(72 * time.Hour) }
+
+	t.Run("stale data is served", func(t *testing.T) {
+		result, err := offline.GetPackage(context.Background(), "http")
+		assert.NoError(t, err)
+		assert.Equal(t, "1.2.0", result.LatestVersion.Version)
+		assert.Equal(t, now, result.FetchedAt)
+	})
+
+	t.Run("missing data is not fetched", func(t *testing.T) {
+		result, err := offline.GetPackage(context.Background(), "path")
+		assert.ErrorIs(t, err, ErrNotCached)
+		assert.Nil(t, result)
+	})
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestCachedAPIService_GetSDKRelease(t *testing.T) {
+	calls := 0
+	service := NewCachedAPIService(&MockAPIService{
+		GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+			calls++
+			return &models.SDKReleaseWrapper{
+				CurrentRelease: models.SDKReleaseHashes{Stable: "abc123"},
+			}, nil
+		},
+	}, t.TempDir(), time.Hour)
+	service.Now = func() time.Time { return time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC) }
+
+	first, err := service.GetSDKRelease(context.Background())
+	assert.NoError(t, err)
+	second, err := service.GetSDKRelease(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, first, second)
+}
+
+func TestCleanCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	service := NewCachedAPIService(&MockAPIService{
+		GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+			return &models.PackageWrapper{Name: packageName}, nil
+		},
+	}, cacheDir, time.Hour)
+
+	_, err := service.GetPackage(context.Background(), "http")
+	assert.NoError(t, err)
+
+	entries, err := os.ReadDir(cacheDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.NoError(t, CleanCache(cacheDir))
+	_, err = os.Stat(cacheDir)
+	assert.True(t, os.IsNotExist(err))
+}
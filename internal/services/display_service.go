@@ -2,13 +2,40 @@ package services
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/sunderee/puby/internal/models"
 )
 
+// sectionTitles maps each dependency section to the title of its output block
+var sectionTitles = map[models.DependencySection]string{
+	models.DependenciesSection:        "Dependency Updates",
+	models.DevDependenciesSection:     "Dev Dependency Updates",
+	models.DependencyOverridesSection: "Dependency Override Updates",
+}
+
+// updateKindColors maps each update kind to the color its new version is printed in
+var updateKindColors = map[models.UpdateKind]string{
+	models.UpdateKindPatch:   "\033[0;32m",
+	models.UpdateKindMinor:   "\033[0;36m",
+	models.UpdateKindMajor:   "\033[0;31m",
+	models.UpdateKindUnknown: "\033[0;37m",
+}
+
+// ansiEscapeSequence matches the escape sequences used to style the output
+var ansiEscapeSequence = regexp.MustCompile("\033\\[[0-9;]*m")
+
 // DisplayService is responsible for displaying updates to the console
-type DisplayService struct{}
+type DisplayService struct {
+	// If this flag is set, the output is printed as plain text without colors,
+	// e.g. because stdout is not a terminal.
+	Plain bool
+}
 
 // NewDisplayService creates a new instance of DisplayService
 func NewDisplayService() DisplayServiceInterface {
@@ -17,45 +44,184 @@ func NewDisplayService() DisplayServiceInterface {
 
 // PrintUpdate prints update information to the console with colorful output
 func (s *DisplayService) PrintUpdate(update *models.Update) {
+	printUpdate(s.output(), update)
+}
+
+// PrintWorkspaceUpdate prints the updates of every project, followed by a
+// summary of all of them
+func (s *DisplayService) PrintWorkspaceUpdate(workspaceUpdate *models.WorkspaceUpdate) {
+	out := s.output()
+
+	for _, project := range workspaceUpdate.Projects {
+		fmt.Fprintf(out, "\033[1;35m>>> %s\033[0m\n", project.PubspecPath)
+		if project.Err != nil {
+			fmt.Fprintf(out, "\033[0;31mError: %v\033[0m\n\n", project.Err)
+			continue
+		}
+
+		// Sections end with a blank line already, the up to date message does not
+		var output strings.Builder
+		printUpdate(&output, project.Update)
+		fmt.Fprint(out, output.String())
+		if !strings.HasSuffix(output.String(), "\n\n") {
+			fmt.Fprintln(out)
+		}
+	}
+
+	printWorkspaceSummary(out, workspaceUpdate.Summary())
+}
+
+// PrintAlignments prints every misaligned dependency with the version it is
+// aligned on and the constraint each project currently requires
+func (s *DisplayService) PrintAlignments(alignments []models.Alignment) {
+	out := s.output()
+
+	fmt.Fprintln(out, "\033[1;36m=== Misaligned Dependencies ===\033[0m")
+	for _, alignment := range alignments {
+		name := alignment.Name
+		if alignment.HostedURL != "" {
+			name += " (" + alignment.HostedURL + ")"
+		}
+		fmt.Fprintf(out, "\033[1;33m%s\033[0m → \033[0;32m%s\033[0m\n", name, alignment.TargetVersion)
+
+		for _, usage := range alignment.Usages {
+			fmt.Fprintf(out, "  %s (%s): %s", usage.PubspecPath, usage.Section, usage.Constraint)
+			if usage.TargetVersion != "" {
+				fmt.Fprintf(out, " \033[2m(held back at %s, %s)\033[0m", usage.TargetVersion, usage.Reason)
+			}
+			fmt.Fprintln(out)
+		}
+	}
+}
+
+// output returns the writer to print to, which strips colors in plain mode
+func (s *DisplayService) output() io.Writer {
+	if s.Plain {
+		return &plainWriter{Writer: os.Stdout}
+	}
+
+	return os.Stdout
+}
+
+// printUpdate prints the updates of a single project
+func printUpdate(out io.Writer, update *models.Update) {
 	if update == nil {
-		fmt.Println("No updates available.")
+		fmt.Fprintln(out, "No updates available.")
 		return
 	}
 
 	// Print environment updates
 	if update.EnvironmentUpdate != nil {
-		printEnvironmentUpdate(update.EnvironmentUpdate)
+		printEnvironmentUpdate(out, update.EnvironmentUpdate)
+	}
+
+	// Print dependency updates, grouped by the pubspec.yaml section they belong to
+	for _, section := range models.DependencySections {
+		deps := dependencyUpdatesIn(update.DependencyUpdates, section)
+		if len(deps) > 0 && hasResolvedVersions(deps) {
+			printDependencyTable(out, sectionTitles[section], deps)
+		} else if len(deps) > 0 {
+			printDependencyUpdates(out, sectionTitles[section], deps)
+		}
+	}
+
+	// Print updates of packages that are only depended on indirectly
+	if len(update.TransitiveUpdates) > 0 {
+		printDependencyTable(out, "Transitive Dependency Updates", update.TransitiveUpdates)
 	}
 
-	// Print dependency updates
-	if len(update.DependencyUpdates) > 0 {
-		printDependencyUpdates(update.DependencyUpdates)
+	// Print updates that annotations in pubspec.yaml hold back
+	if len(update.HeldBack) > 0 {
+		printHeldBack(out, update.HeldBack)
+	}
+
+	// Print packages that could not be checked
+	if len(update.Failures) > 0 {
+		printFailures(out, update.Failures)
+	}
+
+	// Print what is unknown because it was not cached
+	if update.SDKStatusUnknown || len(update.Unknown) > 0 {
+		printUnknown(out, update.SDKStatusUnknown, update.Unknown)
 	}
 
 	// If no updates were printed, show a message
-	if update.EnvironmentUpdate == nil && len(update.DependencyUpdates) == 0 {
-		fmt.Println("Everything is up to date!")
+	if update.EnvironmentUpdate == nil && len(update.DependencyUpdates) == 0 && len(update.TransitiveUpdates) == 0 && len(update.HeldBack) == 0 && len(update.Failures) == 0 &&
+		!update.SDKStatusUnknown && len(update.Unknown) == 0 {
+		fmt.Fprintln(out, "Everything is up to date!")
+	}
+}
+
+// printWorkspaceSummary prints the aggregated results of all projects
+func printWorkspaceSummary(out io.Writer, summary models.WorkspaceSummary) {
+	fmt.Fprintln(out, "\033[1;36m=== Summary ===\033[0m")
+	fmt.Fprintf(out, "Projects checked:      %d\n", summary.Projects)
+	fmt.Fprintf(out, "Projects with updates: %d\n", summary.ProjectsWithUpdates)
+	fmt.Fprintf(out, "Updates:               %d%s\n", summary.Updates, formatUpdateKinds(summary.UpdatesByKind))
+	if summary.Failures > 0 {
+		fmt.Fprintf(out, "Could not check:       %d package(s)\n", summary.Failures)
+	}
+	if summary.ProjectsWithErrors > 0 {
+		fmt.Fprintf(out, "Projects with errors:  \033[0;31m%d\033[0m\n", summary.ProjectsWithErrors)
 	}
 }
 
+// formatUpdateKinds describes how many dependency updates there are of each
+// kind, e.g. " (2 major, 1 patch)", or returns an empty string if there are none
+func formatUpdateKinds(updatesByKind map[models.UpdateKind]int) string {
+	var parts []string
+	for _, kind := range []models.UpdateKind{models.UpdateKindMajor, models.UpdateKindMinor, models.UpdateKindPatch, models.UpdateKindUnknown} {
+		if count := updatesByKind[kind]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%s%d %s\033[0m", updateKindColors[kind], count, kind))
+		}
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
 // printEnvironmentUpdate prints information about environment updates
-func printEnvironmentUpdate(env *models.EnvironmentUpdate) {
-	fmt.Println("\033[1;36m=== SDK Updates ===\033[0m")
+func printEnvironmentUpdate(out io.Writer, env *models.EnvironmentUpdate) {
+	fmt.Fprintln(out, "\033[1;36m=== SDK Updates ===\033[0m")
 
 	if env.DartSDKVersion != nil {
-		fmt.Printf("\033[1;33mDart SDK:\033[0m \033[0;32m%s\033[0m\n", *env.DartSDKVersion)
+		fmt.Fprintf(out, "\033[1;33mDart SDK:\033[0m \033[0;32m%s\033[0m\n", *env.DartSDKVersion)
 	}
 
 	if env.FlutterSDKVersion != nil {
-		fmt.Printf("\033[1;33mFlutter SDK:\033[0m \033[0;32m%s\033[0m\n", *env.FlutterSDKVersion)
+		fmt.Fprintf(out, "\033[1;33mFlutter SDK:\033[0m \033[0;32m%s\033[0m\n", *env.FlutterSDKVersion)
+	}
+
+	if !env.CachedAt.IsZero() {
+		fmt.Fprintf(out, "\033[2m(%s)\033[0m\n", formatCacheAge(env.CachedAt))
 	}
 
-	fmt.Println()
+	fmt.Fprintln(out)
+}
+
+// dependencyUpdatesIn returns the updates that belong to the given section.
+// Updates without a section are treated as regular dependencies.
+func dependencyUpdatesIn(deps []models.DependencyUpdate, section models.DependencySection) []models.DependencyUpdate {
+	var result []models.DependencyUpdate
+	for _, dep := range deps {
+		depSection := dep.Section
+		if depSection == "" {
+			depSection = models.DependenciesSection
+		}
+
+		if depSection == section {
+			result = append(result, dep)
+		}
+	}
+
+	return result
 }
 
 // printDependencyUpdates prints information about dependency updates
-func printDependencyUpdates(deps []models.DependencyUpdate) {
-	fmt.Println("\033[1;36m=== Dependency Updates ===\033[0m")
+func printDependencyUpdates(out io.Writer, title string, deps []models.DependencyUpdate) {
+	fmt.Fprintf(out, "\033[1;36m=== %s ===\033[0m\n", title)
 
 	// Find the maximum length of dependency names for proper alignment
 	maxNameLength := 0
@@ -68,12 +234,185 @@ func printDependencyUpdates(deps []models.DependencyUpdate) {
 	// Print each dependency with proper alignment
 	for _, dep := range deps {
 		namePadding := strings.Repeat(" ", maxNameLength-len(dep.Name))
-		fmt.Printf("\033[1;33m%s\033[0m%s: \033[0;31m%s\033[0m → \033[0;32m%s\033[0m\n",
+		fmt.Fprintf(out, "\033[1;33m%s\033[0m%s: %s → %s%s\033[0m \033[2m(%s)\033[0m\n",
 			dep.Name,
 			namePadding,
 			dep.CurrentVersion,
-			dep.LatestVersion)
+			updateKindColors[dep.Kind()],
+			dep.LatestVersion,
+			updateDetails(dep))
+	}
+
+	fmt.Fprintln(out)
+}
+
+// updateDetails describes a dependency update after its latest version: the
+// kind of update, where the dependency comes from if it isn't pub.dev, and
+// how old its data is if it was served from the cache
+func updateDetails(dep models.DependencyUpdate) string {
+	details := string(dep.Kind())
+	switch dep.Source {
+	case models.GitSource:
+		details += ", git"
+	case models.PathSource:
+		details += ", local"
+	}
+	if dep.HostedURL != "" {
+		details += ", " + dep.HostedURL
+	}
+	if !dep.CachedAt.IsZero() {
+		details += ", " + formatCacheAge(dep.CachedAt)
+	}
+
+	return details
+}
+
+// hasResolvedVersions reports whether pubspec.lock resolved any of the updates
+func hasResolvedVersions(deps []models.DependencyUpdate) bool {
+	for _, dep := range deps {
+		if dep.ResolvedVersion != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// printDependencyTable prints dependency updates as a table with the declared
+// constraint, the version resolved in pubspec.lock and the latest version.
+// Missing values are shown as "-".
+func printDependencyTable(out io.Writer, title string, deps []models.DependencyUpdate) {
+	fmt.Fprintf(out, "\033[1;36m=== %s ===\033[0m\n", title)
+
+	orDash := func(value string) string {
+		if value == "" {
+			return "-"
+		}
+		return value
+	}
+
+	// Find the width of each column for proper alignment
+	nameWidth, constraintWidth, resolvedWidth := len("Package"), len("Constraint"), len("Resolved")
+	for _, dep := range deps {
+		nameWidth = max(nameWidth, len(dep.Name))
+		constraintWidth = max(constraintWidth, len(orDash(dep.Constraint)))
+		resolvedWidth = max(resolvedWidth, len(orDash(dep.ResolvedVersion)))
+	}
+
+	fmt.Fprintf(out, "\033[2m%-*s  %-*s  %-*s  %s\033[0m\n",
+		nameWidth, "Package",
+		constraintWidth, "Constraint",
+		resolvedWidth, "Resolved",
+		"Latest")
+
+	for _, dep := range deps {
+		fmt.Fprintf(out, "\033[1;33m%-*s\033[0m  %-*s  %-*s  %s%s\033[0m \033[2m(%s)\033[0m\n",
+			nameWidth, dep.Name,
+			constraintWidth, orDash(dep.Constraint),
+			resolvedWidth, orDash(dep.ResolvedVersion),
+			updateKindColors[dep.Kind()],
+			dep.LatestVersion,
+			updateDetails(dep))
+	}
+
+	fmt.Fprintln(out)
+}
+
+// printHeldBack prints the updates that annotations hold back and why
+func printHeldBack(out io.Writer, heldBack []models.HeldBackUpdate) {
+	fmt.Fprintln(out, "\033[1;36m=== Held Back ===\033[0m")
+
+	// Find the maximum length of package names for proper alignment
+	maxNameLength := 0
+	for _, dep := range heldBack {
+		if len(dep.Name) > maxNameLength {
+			maxNameLength = len(dep.Name)
+		}
+	}
+
+	for _, dep := range heldBack {
+		namePadding := strings.Repeat(" ", maxNameLength-len(dep.Name))
+
+		fmt.Fprintf(out, "\033[1;33m%s\033[0m%s: %s → \033[0;37m%s\033[0m (%s)\n", dep.Name, namePadding, dep.CurrentVersion, dep.LatestVersion, dep.Reason)
+	}
+
+	fmt.Fprintln(out)
+}
+
+// printFailures prints the packages that could not be checked and why
+func printFailures(out io.Writer, failures []models.PackageFailure) {
+	fmt.Fprintln(out, "\033[1;36m=== Could not check ===\033[0m")
+
+	// Find the maximum length of package names for proper alignment
+	maxNameLength := 0
+	for _, failure := range failures {
+		if len(failure.Name) > maxNameLength {
+			maxNameLength = len(failure.Name)
+		}
+	}
+
+	for _, failure := range failures {
+		namePadding := strings.Repeat(" ", maxNameLength-len(failure.Name))
+
+		fmt.Fprintf(out, "\033[1;33m%s\033[0m%s: \033[0;31m%s\033[0m\n", failure.Name, namePadding, failureReason(failure))
+	}
+
+	fmt.Fprintln(out)
+}
+
+// failureReason describes why a package could not be checked
+func failureReason(failure models.PackageFailure) string {
+	if failure.StatusCode != 0 {
+		return fmt.Sprintf("HTTP %d %s", failure.StatusCode, http.StatusText(failure.StatusCode))
+	}
+	if failure.Cause != nil {
+		return failure.Cause.Error()
+	}
+
+	return "unknown error"
+}
+
+// printUnknown prints what could not be checked because no cached data was
+// available while running offline
+func printUnknown(out io.Writer, sdkStatusUnknown bool, packageNames []string) {
+	fmt.Fprintln(out, "\033[1;36m=== Unknown (not cached) ===\033[0m")
+
+	if sdkStatusUnknown {
+		fmt.Fprintln(out, "\033[1;33mSDKs\033[0m")
+	}
+
+	for _, packageName := range packageNames {
+		fmt.Fprintf(out, "\033[1;33m%s\033[0m\n", packageName)
+	}
+
+	fmt.Fprintln(out)
+}
+
+// plainWriter strips styling escape sequences from everything written to it.
+// Every write holds whole sequences, as each line is printed with one call.
+type plainWriter struct {
+	Writer io.Writer
+}
+
+func (w *plainWriter) Write(p []byte) (int, error) {
+	if _, err := w.Writer.Write(ansiEscapeSequence.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// formatCacheAge describes how long ago cached data was fetched
+func formatCacheAge(cachedAt time.Time) string {
+	age := time.Since(cachedAt)
+	switch {
+	case age < time.Minute:
+		return "cached just now"
+	case age < time.Hour:
+		return fmt.Sprintf("cached %dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("cached %dh ago", int(age.Hours()))
 	}
 
-	fmt.Println()
+	return fmt.Sprintf("cached %dd ago", int(age.Hours()/24))
 }
@@ -7,4 +7,5 @@ import (
 // DisplayServiceInterface defines the interface for display service operations
 type DisplayServiceInterface interface {
 	PrintUpdate(update *models.Update)
+	PrintWorkspaceUpdate(workspaceUpdate *models.WorkspaceUpdate)
 }
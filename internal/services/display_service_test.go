@@ -2,9 +2,12 @@ package services
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/sunderee/puby/internal/models"
@@ -120,6 +123,262 @@ func TestDisplayService_PrintUpdate(t *testing.T) {
 		assert.Contains(t, output, "1.8.3")
 	})
 
+	t.Run("Dependency updates grouped by section", func(t *testing.T) {
+		// Reset output capture
+		r, w, _ = os.Pipe()
+		os.Stdout = w
+
+		// Create test data
+		update := &models.Update{
+			DependencyUpdates: []models.DependencyUpdate{
+				{
+					Name:           "http",
+					CurrentVersion: "1.1.0",
+					LatestVersion:  "1.2.0",
+					Section:        models.DependenciesSection,
+				},
+				{
+					Name:           "lints",
+					CurrentVersion: "4.0.0",
+					LatestVersion:  "5.0.0",
+					Section:        models.DevDependenciesSection,
+				},
+				{
+					Name:           "http",
+					CurrentVersion: "1.0.0",
+					LatestVersion:  "1.2.0",
+					Section:        models.DependencyOverridesSection,
+				},
+			},
+		}
+
+		// Call the method
+		displayService.PrintUpdate(update)
+		output := getCapturedOutput()
+
+		// Assert
+		assert.Contains(t, output, "=== Dependency Updates ===")
+		assert.Contains(t, output, "=== Dev Dependency Updates ===")
+		assert.Contains(t, output, "=== Dependency Override Updates ===")
+		assert.Less(t, strings.Index(output, "Dependency Updates"), strings.Index(output, "Dev Dependency Updates"))
+		assert.Less(t, strings.Index(output, "Dev Dependency Updates"), strings.Index(output, "Dependency Override Updates"))
+	})
+
+	t.Run("Dependency updates classified by kind", func(t *testing.T) {
+		// Reset output capture
+		r, w, _ = os.Pipe()
+		os.Stdout = w
+
+		// Create test data
+		update := &models.Update{
+			DependencyUpdates: []models.DependencyUpdate{
+				{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "1.1.2"},
+				{Name: "path", CurrentVersion: "1.8.0", LatestVersion: "1.9.1"},
+				{Name: "provider", CurrentVersion: "5.0.0", LatestVersion: "6.1.4"},
+			},
+		}
+
+		// Call the method
+		displayService.PrintUpdate(update)
+		output := getCapturedOutput()
+
+		// Assert
+		assert.Contains(t, output, "\033[0;32m1.1.2\033[0m \033[2m(patch)")
+		assert.Contains(t, output, "\033[0;36m1.9.1\033[0m \033[2m(minor)")
+		assert.Contains(t, output, "\033[0;31m6.1.4\033[0m \033[2m(major)")
+	})
+
+	t.Run("Failures", func(t *testing.T) {
+		// Reset output capture
+		r, w, _ = os.Pipe()
+		os.Stdout = w
+
+		// Create test data
+		update := &models.Update{
+			DependencyUpdates: []models.DependencyUpdate{
+				{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "1.2.0"},
+			},
+			Failures: []models.PackageFailure{
+				{Name: "private_pkg", StatusCode: 404, Cause: &HTTPStatusError{StatusCode: 404}},
+				{Name: "flaky", Cause: errors.New("connection reset by peer")},
+			},
+		}
+
+		// Call the method
+		displayService.PrintUpdate(update)
+		output := getCapturedOutput()
+
+		// Assert
+		assert.Contains(t, output, "Dependency Updates")
+		assert.Contains(t, output, "=== Could not check ===")
+		assert.Contains(t, output, "HTTP 404 Not Found")
+		assert.Contains(t, output, "connection reset by peer")
+		assert.NotContains(t, output, "Everything is up to date!")
+	})
+
+	t.Run("Offline results", func(t *testing.T) {
+		// Reset output capture
+		r, w, _ = os.Pipe()
+		os.Stdout = w
+
+		// Create test data
+		update := &models.Update{
+			DependencyUpdates: []models.DependencyUpdate{
+				{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "1.2.0", CachedAt: time.Now().Add(-3 * time.Hour)},
+			},
+			Unknown:          []string{"uncached_pkg"},
+			SDKStatusUnknown: true,
+		}
+
+		// Call the method
+		displayService.PrintUpdate(update)
+		output := getCapturedOutput()
+
+		// Assert
+		assert.Contains(t, output, "(minor, cached 3h ago)")
+		assert.Contains(t, output, "=== Unknown (not cached) ===")
+		assert.Contains(t, output, "SDKs")
+		assert.Contains(t, output, "uncached_pkg")
+		assert.NotContains(t, output, "Everything is up to date!")
+	})
+
+	t.Run("Resolved versions from the lockfile", func(t *testing.T) {
+		// Reset output capture
+		r, w, _ = os.Pipe()
+		os.Stdout = w
+
+		// Create test data
+		update := &models.Update{
+			DependencyUpdates: []models.DependencyUpdate{
+				{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "1.2.0", Constraint: "^1.1.0", ResolvedVersion: "1.1.4"},
+				{Name: "provider", CurrentVersion: "6.0.0", LatestVersion: "6.1.0", Constraint: "^6.0.0"},
+				{Name: "forked", CurrentVersion: "v1.0.0", LatestVersion: "v1.1.0", ResolvedVersion: "1.0.0", Source: models.GitSource},
+				{Name: "core", CurrentVersion: "2.0.0", LatestVersion: "2.1.0", Constraint: "^2.0.0", Source: models.PathSource},
+			},
+			TransitiveUpdates: []models.DependencyUpdate{
+				{Name: "meta", CurrentVersion: "1.9.0", LatestVersion: "1.11.0", ResolvedVersion: "1.9.0"},
+			},
+		}
+
+		// Call the method
+		displayService.PrintUpdate(update)
+		output := getCapturedOutput()
+
+		// Assert
+		assert.Contains(t, output, "Package   Constraint  Resolved  Latest")
+		assert.Contains(t, output, "http    \033[0m  ^1.1.0      1.1.4     \033[0;36m1.2.0")
+		assert.Contains(t, output, "provider\033[0m  ^6.0.0      -         \033[0;36m6.1.0")
+		assert.Contains(t, output, "forked  \033[0m  -           1.0.0     \033[0;36mv1.1.0\033[0m \033[2m(minor, git)")
+		assert.Contains(t, output, "core    \033[0m  ^2.0.0      -         \033[0;36m2.1.0\033[0m \033[2m(minor, local)")
+		assert.Contains(t, output, "=== Transitive Dependency Updates ===")
+		assert.Contains(t, output, "meta   \033[0m  -           1.9.0     \033[0;36m1.11.0")
+	})
+
+	t.Run("Held back updates", func(t *testing.T) {
+		// Reset output capture
+		r, w, _ = os.Pipe()
+		os.Stdout = w
+
+		// Create test data
+		update := &models.Update{
+			HeldBack: []models.HeldBackUpdate{
+				{
+					DependencyUpdate: models.DependencyUpdate{Name: "intl", CurrentVersion: "0.18.0", LatestVersion: "0.20.2"},
+					Reason:           "ignored: pinned by flutter_localizations",
+				},
+				{
+					DependencyUpdate: models.DependencyUpdate{Name: "http", CurrentVersion: "0.13.0", LatestVersion: "1.2.0"},
+					Reason:           "max 0.13",
+				},
+			},
+		}
+
+		// Call the method
+		displayService.PrintUpdate(update)
+		output := getCapturedOutput()
+
+		// Assert
+		assert.Contains(t, output, "=== Held Back ===")
+		assert.Contains(t, output, "intl\033[0m: 0.18.0 → \033[0;37m0.20.2\033[0m (ignored: pinned by flutter_localizations)")
+		assert.Contains(t, output, "http\033[0m: 0.13.0 → \033[0;37m1.2.0\033[0m (max 0.13)")
+		assert.NotContains(t, output, "Everything is up to date!")
+	})
+
 	// Restore stdout
 	os.Stdout = originalStdout
 }
+
+func TestDisplayService_PrintUpdate_Plain(t *testing.T) {
+	// Prepare for capturing stdout
+	originalStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	update := &models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "http", CurrentVersion: "1.2.0", LatestVersion: "2.0.0"},
+		},
+	}
+
+	displayService := &DisplayService{Plain: true}
+	displayService.PrintUpdate(update)
+
+	_ = w.Close()
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	os.Stdout = originalStdout
+	output := buf.String()
+
+	// Assert
+	assert.Contains(t, output, "=== Dependency Updates ===")
+	assert.Contains(t, output, "http: 1.2.0 → 2.0.0 (major)")
+	assert.NotContains(t, output, "\033[")
+}
+
+func TestDisplayService_PrintWorkspaceUpdate(t *testing.T) {
+	// Prepare for capturing stdout
+	originalStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	workspaceUpdate := &models.WorkspaceUpdate{
+		RootDir: "/repo",
+		Projects: []models.ProjectUpdate{
+			{
+				PubspecPath: "apps/mobile/pubspec.yaml",
+				Update: &models.Update{
+					DependencyUpdates: []models.DependencyUpdate{
+						{Name: "http", CurrentVersion: "1.2.0", LatestVersion: "2.0.0"},
+						{Name: "path", CurrentVersion: "1.8.0", LatestVersion: "1.8.3"},
+					},
+				},
+			},
+			{
+				PubspecPath: "packages/core/pubspec.yaml",
+				Update:      &models.Update{},
+			},
+			{
+				PubspecPath: "packages/broken/pubspec.yaml",
+				Err:         errors.New("failed to parse pubspec.yaml"),
+			},
+		},
+	}
+
+	displayService := &DisplayService{Plain: true}
+	displayService.PrintWorkspaceUpdate(workspaceUpdate)
+
+	_ = w.Close()
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	os.Stdout = originalStdout
+	output := buf.String()
+
+	// Assert
+	assert.Contains(t, output, ">>> apps/mobile/pubspec.yaml\n=== Dependency Updates ===\nhttp: 1.2.0 → 2.0.0 (major)\n")
+	assert.Contains(t, output, ">>> packages/core/pubspec.yaml\nEverything is up to date!\n")
+	assert.Contains(t, output, ">>> packages/broken/pubspec.yaml\nError: failed to parse pubspec.yaml\n")
+	assert.Contains(t, output, "Projects checked:      3\n")
+	assert.Contains(t, output, "Projects with updates: 1\n")
+	assert.Contains(t, output, "Updates:               2 (1 major, 1 patch)\n")
+	assert.Contains(t, output, "Projects with errors:  1\n")
+}
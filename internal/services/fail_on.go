@@ -0,0 +1,38 @@
+package services
+
+import (
+	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/models"
+)
+
+// ShouldFail reports whether the update contains any update the fail-on
+// setting treats as a failure. Updates whose kind is unknown only count for
+// FailOnAny.
+func ShouldFail(update *models.Update, failOn config.FailOn) bool {
+	if update == nil {
+		return false
+	}
+
+	hasSDKUpdate := update.EnvironmentUpdate != nil &&
+		(update.EnvironmentUpdate.DartSDKVersion != nil || update.EnvironmentUpdate.FlutterSDKVersion != nil)
+
+	var deps []models.DependencyUpdate
+	deps = append(deps, update.DependencyUpdates...)
+	deps = append(deps, update.TransitiveUpdates...)
+
+	switch failOn {
+	case config.FailOnAny:
+		return hasSDKUpdate || len(deps) > 0
+	case config.FailOnSDK:
+		return hasSDKUpdate
+	case config.FailOnMajor, config.FailOnMinor:
+		for _, dep := range deps {
+			kind := dep.Kind()
+			if kind == models.UpdateKindMajor || (failOn == config.FailOnMinor && kind == models.UpdateKindMinor) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestShouldFail(t *testing.T) {
+	dartVersion := "3.5.0"
+	sdkUpdate := &models.Update{
+		EnvironmentUpdate: &models.EnvironmentUpdate{DartSDKVersion: &dartVersion},
+	}
+	patchUpdate := &models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "http", CurrentVersion: "1.2.0", LatestVersion: "1.2.1"},
+		},
+	}
+	minorUpdate := &models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "http", CurrentVersion: "1.2.0", LatestVersion: "1.3.0"},
+		},
+	}
+	majorTransitiveUpdate := &models.Update{
+		TransitiveUpdates: []models.DependencyUpdate{
+			{Name: "meta", CurrentVersion: "1.2.0", LatestVersion: "2.0.0"},
+		},
+	}
+	unknownUpdate := &models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "forked", CurrentVersion: "main", LatestVersion: "v1.0.0", Source: models.GitSource},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		update   *models.Update
+		failOn   config.FailOn
+		expected bool
+	}{
+		{name: "No update", update: nil, failOn: config.FailOnAny, expected: false},
+		{name: "Up to date", update: &models.Update{}, failOn: config.FailOnAny, expected: false},
+		{name: "Any with SDK update", update: sdkUpdate, failOn: config.FailOnAny, expected: true},
+		{name: "Any with patch update", update: patchUpdate, failOn: config.FailOnAny, expected: true},
+		{name: "Any with unknown kind", update: unknownUpdate, failOn: config.FailOnAny, expected: true},
+		{name: "SDK with SDK update", update: sdkUpdate, failOn: config.FailOnSDK, expected: true},
+		{name: "SDK with dependency update", update: minorUpdate, failOn: config.FailOnSDK, expected: false},
+		{name: "Minor with patch update", update: patchUpdate, failOn: config.FailOnMinor, expected: false},
+		{name: "Minor with minor update", update: minorUpdate, failOn: config.FailOnMinor, expected: true},
+		{name: "Minor with major update", update: majorTransitiveUpdate, failOn: config.FailOnMinor, expected: true},
+		{name: "Major with minor update", update: minorUpdate, failOn: config.FailOnMajor, expected: false},
+		{name: "Major with major update", update: majorTransitiveUpdate, failOn: config.FailOnMajor, expected: true},
+		{name: "Major with unknown kind", update: unknownUpdate, failOn: config.FailOnMajor, expected: false},
+		{name: "Major with SDK update", update: sdkUpdate, failOn: config.FailOnMajor, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ShouldFail(tt.update, tt.failOn))
+		})
+	}
+}
@@ -7,4 +7,8 @@ import (
 // FileWriterInterface defines the interface for writing updates to files
 type FileWriterInterface interface {
 	WriteUpdates(update *models.Update) error
+	PreviewUpdates(update *models.Update) (original []byte, updated []byte, err error)
+	RestoreBackup() error
+	DiscardBackup() error
+	WriteContent(content []byte) error
 }
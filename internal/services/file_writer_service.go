@@ -3,15 +3,24 @@ package services
 import (
 	"fmt"
 	"os"
-	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/sunderee/puby/internal/models"
+	"github.com/sunderee/puby/internal/parsers"
+	"github.com/sunderee/puby/internal/semver"
+	"gopkg.in/yaml.v3"
 )
 
+const BACKUP_FILE_SUFFIX = ".bak"
+
 // FileWriterService is responsible for writing updates to the pubspec.yaml file
 type FileWriterService struct {
 	PubspecFilePath string
+
+	// If this flag is set, the previous content of pubspec.yaml is kept next to
+	// it with BACKUP_FILE_SUFFIX appended, so the write can be restored.
+	Backup bool
 }
 
 // NewFileWriterService creates a new instance of FileWriterService
@@ -21,88 +30,257 @@ func NewFileWriterService(pubspecFilePath string) FileWriterInterface {
 	}
 }
 
-// WriteUpdates writes the updates to the pubspec.yaml file
+// WriteUpdates writes the updates to the pubspec.yaml file. The file is
+// replaced atomically and keeps its permissions.
 func (s *FileWriterService) WriteUpdates(update *models.Update) error {
+	original, content, err := s.PreviewUpdates(update)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(s.PubspecFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read pubspec.yaml: %v", err)
+	}
+
+	// Keep the previous content around first, so there is always something to
+	// restore once the file has changed
+	if s.Backup {
+		if err := writeFileAtomically(s.backupFilePath(), original, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to back up pubspec.yaml: %v", err)
+		}
+	}
+
+	// Write the updated content back to the file
+	if err := writeFileAtomically(s.PubspecFilePath, content, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write pubspec.yaml: %v", err)
+	}
+
+	return nil
+}
+
+// WriteContent replaces the content of the pubspec.yaml file as is, atomically
+// and keeping its permissions. It is used to revert updates.
+func (s *FileWriterService) WriteContent(content []byte) error {
+	info, err := os.Stat(s.PubspecFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read pubspec.yaml: %v", err)
+	}
+
+	if err := writeFileAtomically(s.PubspecFilePath, content, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write pubspec.yaml: %v", err)
+	}
+
+	return nil
+}
+
+// RestoreBackup puts back the content pubspec.yaml had before the last write
+// that made a backup, and removes the backup
+func (s *FileWriterService) RestoreBackup() error {
+	backupFilePath := s.backupFilePath()
+	content, err := os.ReadFile(backupFilePath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no backup found at %s", backupFilePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %v", err)
+	}
+
+	info, err := os.Stat(backupFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %v", err)
+	}
+
+	if err := writeFileAtomically(s.PubspecFilePath, content, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to restore pubspec.yaml: %v", err)
+	}
+
+	return os.Remove(backupFilePath)
+}
+
+// DiscardBackup removes the backup the last write made, for writes that were
+// reverted. Nothing is removed if backups are off.
+func (s *FileWriterService) DiscardBackup() error {
+	if !s.Backup {
+		return nil
+	}
+
+	if err := os.Remove(s.backupFilePath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove backup: %v", err)
+	}
+
+	return nil
+}
+
+// backupFilePath returns the path the previous content of pubspec.yaml is kept at
+func (s *FileWriterService) backupFilePath() string {
+	return s.PubspecFilePath + BACKUP_FILE_SUFFIX
+}
+
+// PreviewUpdates returns the current content of the pubspec.yaml file and the
+// content it would have with the updates applied, without writing anything
+func (s *FileWriterService) PreviewUpdates(update *models.Update) ([]byte, []byte, error) {
 	if update == nil {
-		return fmt.Errorf("no updates to write")
+		return nil, nil, fmt.Errorf("no updates to write")
 	}
 
 	// Read the file
 	fileContent, err := os.ReadFile(s.PubspecFilePath)
 	if err != nil {
-		return fmt.Errorf("failed to read pubspec.yaml: %v", err)
+		return nil, nil, fmt.Errorf("failed to read pubspec.yaml: %v", err)
 	}
 
-	// Convert to string for easier manipulation
-	content := string(fileContent)
+	content, err := applyUpdates(fileContent, update)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fileContent, content, nil
+}
+
+// yamlEdit replaces a byte range of the file with new text
+type yamlEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// applyUpdates returns the content of pubspec.yaml with the updates applied.
+// Only the scalars holding the updated values are replaced, keeping their
+// quoting, so comments, key order and line endings stay as they are. If two
+// updates change the same value, e.g. the published and the local version of
+// an overridden package, the first one is written. It fails without changing
+// anything if any of the values to update is not found.
+func applyUpdates(content []byte, update *models.Update) ([]byte, error) {
+	document, err := parsers.ParsePubspecDocument(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pubspec.yaml: %v", err)
+	}
+
+	var edits []yamlEdit
+	edited := make(map[*yaml.Node]bool)
+	addEdit := func(node *yaml.Node, value string) error {
+		if value == node.Value || edited[node] {
+			return nil
+		}
+
+		start, end, err := document.ScalarSpan(node)
+		if err != nil {
+			return err
+		}
+
+		edited[node] = true
+		edits = append(edits, yamlEdit{start: start, end: end, text: parsers.FormatScalar(node.Style, value)})
+		return nil
+	}
 
 	// Apply SDK updates if needed
 	if update.EnvironmentUpdate != nil {
-		if update.EnvironmentUpdate.DartSDKVersion != nil {
-			content = s.updateDartSDKVersion(content, *update.EnvironmentUpdate.DartSDKVersion)
+		sdkUpdates := []struct {
+			key     string
+			version *string
+		}{
+			{key: "sdk", version: update.EnvironmentUpdate.DartSDKVersion},
+			{key: "flutter", version: update.EnvironmentUpdate.FlutterSDKVersion},
 		}
-		if update.EnvironmentUpdate.FlutterSDKVersion != nil {
-			content = s.updateFlutterSDKVersion(content, *update.EnvironmentUpdate.FlutterSDKVersion)
+
+		for _, sdkUpdate := range sdkUpdates {
+			if sdkUpdate.version == nil {
+				continue
+			}
+
+			node := document.Lookup("environment", sdkUpdate.key)
+			if node == nil {
+				return nil, fmt.Errorf("environment.%s not found in pubspec.yaml", sdkUpdate.key)
+			}
+			constraint, err := rewriteConstraint(node.Value, *sdkUpdate.version)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update environment.%s: %v", sdkUpdate.key, err)
+			}
+			if err := addEdit(node, constraint); err != nil {
+				return nil, fmt.Errorf("failed to update environment.%s: %v", sdkUpdate.key, err)
+			}
 		}
 	}
 
-	// Apply dependency updates
+	// Apply dependency updates, each within the section it was declared in
 	for _, dep := range update.DependencyUpdates {
-		content = s.updateDependencyVersion(content, dep.Name, dep.LatestVersion)
+		section := dep.Section
+		if section == "" {
+			section = models.DependenciesSection
+		}
+
+		node, err := dependencyValueNode(document, section, dep)
+		if err != nil {
+			return nil, err
+		}
+
+		value := dep.LatestVersion
+		if dep.Source != models.GitSource {
+			value, err = rewriteConstraint(node.Value, dep.LatestVersion)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update %s in %s: %v", dep.Name, section, err)
+			}
+		}
+		if err := addEdit(node, value); err != nil {
+			return nil, fmt.Errorf("failed to update %s in %s: %v", dep.Name, section, err)
+		}
 	}
 
-	// Write the updated content back to the file
-	return os.WriteFile(s.PubspecFilePath, []byte(content), 0644)
-}
+	// Apply the edits from the end of the file backwards, so the offsets of
+	// the remaining edits stay valid
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
 
-// updateDartSDKVersion updates the Dart SDK version in the pubspec.yaml file
-func (s *FileWriterService) updateDartSDKVersion(content, newVersion string) string {
-	// Look for sdk: "..." or sdk: '...' pattern in the environment section
-	sdkPattern := regexp.MustCompile(`(environment:[\s\S]*?sdk:[\s]*['"])([^'"]*?)(['"])`)
-	return sdkPattern.ReplaceAllString(content, "${1}"+newVersion+"${3}")
-}
+	result := string(content)
+	for _, edit := range edits {
+		result = result[:edit.start] + edit.text + result[edit.end:]
+	}
 
-// updateFlutterSDKVersion updates the Flutter SDK version in the pubspec.yaml file
-func (s *FileWriterService) updateFlutterSDKVersion(content, newVersion string) string {
-	// Look for flutter: "..." or flutter: '...' pattern in the environment section
-	flutterPattern := regexp.MustCompile(`(environment:[\s\S]*?flutter:[\s]*['"])([^'"]*?)(['"])`)
-	return flutterPattern.ReplaceAllString(content, "${1}"+newVersion+"${3}")
+	return []byte(result), nil
 }
 
-// updateDependencyVersion updates a specific dependency version in the pubspec.yaml file
-func (s *FileWriterService) updateDependencyVersion(content, dependencyName, newVersion string) string {
-	// This pattern matches:
-	//   dependencyName: "any-version"
-	//   dependencyName: ^0.13.3
-	//   dependencyName: '~1.2.3'
-	// with any amount of whitespace and quotes
-	dep := regexp.QuoteMeta(dependencyName)
-	pattern := regexp.MustCompile(`(\n\s*` + dep + `:\s*\^?~?)([\d\.]+)`)
-
-	// Find all matches
-	matches := pattern.FindStringSubmatch(content)
-	if len(matches) > 2 {
-		// Get the prefix (includes whitespace, name, and any version constraint like ^, ~)
-		prefix := matches[1]
-		return pattern.ReplaceAllString(content, prefix+newVersion)
-	}
-
-	return content
+// dependencyValueNode finds the node holding the value to update for a
+// dependency: the constraint of a hosted dependency, either given directly or
+// under its version key, or the ref of a git dependency
+func dependencyValueNode(document *parsers.PubspecDocument, section models.DependencySection, dep models.DependencyUpdate) (*yaml.Node, error) {
+	node := document.Lookup(string(section), dep.Name)
+	if node == nil {
+		return nil, fmt.Errorf("dependency %s not found in %s", dep.Name, section)
+	}
+
+	var key []string
+	switch {
+	case dep.Source == models.GitSource:
+		key = []string{"git", "ref"}
+	case node.Kind == yaml.MappingNode:
+		key = []string{"version"}
+	}
+
+	if len(key) > 0 {
+		path := append([]string{string(section), dep.Name}, key...)
+		node = document.Lookup(path...)
+		if node == nil {
+			return nil, fmt.Errorf("%s not found in %s", strings.Join(path[1:], "."), section)
+		}
+	}
+
+	if node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+		return nil, fmt.Errorf("dependency %s in %s has no version to update", dep.Name, section)
+	}
+
+	return node, nil
 }
 
-// extractVersionPrefix extracts the version constraint prefix (^, ~, >=, etc.) from a version string
-func extractVersionPrefix(version string) string {
-	// Check for common version constraints
-	if strings.HasPrefix(version, "^") {
-		return "^"
-	} else if strings.HasPrefix(version, "~") {
-		return "~"
-	} else if strings.HasPrefix(version, ">=") {
-		return ">="
-	} else if strings.HasPrefix(version, ">") {
-		return ">"
-	}
-
-	// No constraint found
-	return ""
+// rewriteConstraint moves a constraint up to the new version in the same style
+// it is written in, e.g. "^1.2.0" becomes "^1.3.0" and ">=1.2.0 <2.0.0" becomes
+// ">=1.3.0 <2.0.0"
+func rewriteConstraint(constraint, newVersion string) (string, error) {
+	version, err := semver.Parse(newVersion)
+	if err != nil {
+		return "", err
+	}
+
+	return semver.RewriteConstraint(constraint, version)
 }
@@ -160,6 +160,265 @@ dependencies:
 `,
 			expectError: false,
 		},
+		{
+			name: "update dev dependency only in its section",
+			initialContent: `name: test_app
+environment:
+  sdk: "2.18.0"
+dependencies:
+  lints: ^2.0.0
+dev_dependencies:
+  lints: ^2.0.0
+  test: ^1.24.0
+`,
+			update: &models.Update{
+				DependencyUpdates: []models.DependencyUpdate{
+					{
+						Name:           "lints",
+						CurrentVersion: "2.0.0",
+						LatestVersion:  "5.0.0",
+						Section:        models.DevDependenciesSection,
+					},
+				},
+			},
+			expectedContent: `name: test_app
+environment:
+  sdk: "2.18.0"
+dependencies:
+  lints: ^2.0.0
+dev_dependencies:
+  lints: ^5.0.0
+  test: ^1.24.0
+`,
+			expectError: false,
+		},
+		{
+			name: "update dependency override",
+			initialContent: `name: test_app
+dependencies:
+  http: ^1.1.0
+dependency_overrides:
+  http: 1.1.0
+`,
+			update: &models.Update{
+				DependencyUpdates: []models.DependencyUpdate{
+					{
+						Name:           "http",
+						CurrentVersion: "1.1.0",
+						LatestVersion:  "1.2.0",
+						Section:        models.DependencyOverridesSection,
+					},
+				},
+			},
+			expectedContent: `name: test_app
+dependencies:
+  http: ^1.1.0
+dependency_overrides:
+  http: 1.2.0
+`,
+			expectError: false,
+		},
+		{
+			name: "update hosted dependency declared as a map",
+			initialContent: `name: test_app
+dependencies:
+  internal_pkg:
+    hosted: https://pub.example.com
+    version: ^2.0.0
+  other_pkg:
+    hosted: https://pub.example.com
+    version: ^2.0.0
+dev_dependencies:
+  test: ^1.24.0
+`,
+			update: &models.Update{
+				DependencyUpdates: []models.DependencyUpdate{
+					{
+						Name:           "other_pkg",
+						CurrentVersion: "2.0.0",
+						LatestVersion:  "2.3.0",
+						Section:        models.DependenciesSection,
+						HostedURL:      "https://pub.example.com",
+					},
+				},
+			},
+			expectedContent: `name: test_app
+dependencies:
+  internal_pkg:
+    hosted: https://pub.example.com
+    version: ^2.0.0
+  other_pkg:
+    hosted: https://pub.example.com
+    version: ^2.3.0
+dev_dependencies:
+  test: ^1.24.0
+`,
+			expectError: false,
+		},
+		{
+			name: "update git dependency ref",
+			initialContent: `name: test_app
+dependencies:
+  forked:
+    git:
+      url: https://github.com/example/forked.git
+      ref: "v1.2.0" # pinned
+  http: ^0.13.3
+dependency_overrides:
+  forked:
+    git:
+      url: https://github.com/example/forked.git
+      ref: v1.2.0
+`,
+			update: &models.Update{
+				DependencyUpdates: []models.DependencyUpdate{
+					{Name: "forked", CurrentVersion: "v1.2.0", LatestVersion: "v1.3.0", Section: models.DependenciesSection, Source: models.GitSource},
+				},
+			},
+			expectedContent: `name: test_app
+dependencies:
+  forked:
+    git:
+      url: https://github.com/example/forked.git
+      ref: "v1.3.0" # pinned
+  http: ^0.13.3
+dependency_overrides:
+  forked:
+    git:
+      url: https://github.com/example/forked.git
+      ref: v1.2.0
+`,
+			expectError: false,
+		},
+		{
+			name: "preserve comments, quoting and key order",
+			initialContent: `name: test_app # the app
+environment:
+  sdk: '2.18.0' # keep in sync with CI
+dependencies:
+  # networking
+  http: "^0.13.3" # pinned for now
+  path: ^1.8.0
+`,
+			update: &models.Update{
+				EnvironmentUpdate: &models.EnvironmentUpdate{
+					DartSDKVersion: createStringPtr("2.19.0"),
+				},
+				DependencyUpdates: []models.DependencyUpdate{
+					{Name: "http", CurrentVersion: "0.13.3", LatestVersion: "1.2.0", Section: models.DependenciesSection},
+				},
+			},
+			expectedContent: `name: test_app # the app
+environment:
+  sdk: '2.19.0' # keep in sync with CI
+dependencies:
+  # networking
+  http: "^1.2.0" # pinned for now
+  path: ^1.8.0
+`,
+			expectError: false,
+		},
+		{
+			name: "update dependency declared in several sections only once",
+			initialContent: `name: test_app
+dependencies:
+  meta: ^1.8.0
+dev_dependencies:
+  meta: ^1.8.0
+`,
+			update: &models.Update{
+				DependencyUpdates: []models.DependencyUpdate{
+					{Name: "meta", CurrentVersion: "1.8.0", LatestVersion: "1.11.0", Section: models.DevDependenciesSection},
+				},
+			},
+			expectedContent: `name: test_app
+dependencies:
+  meta: ^1.8.0
+dev_dependencies:
+  meta: ^1.11.0
+`,
+			expectError: false,
+		},
+		{
+			name:           "only update sdk inside environment",
+			initialContent: "name: test_app\r\ndependencies:\r\n  flutter:\r\n    sdk: flutter\r\nenvironment:\r\n  sdk: \"2.18.0\"\r\n",
+			update: &models.Update{
+				EnvironmentUpdate: &models.EnvironmentUpdate{
+					DartSDKVersion: createStringPtr("2.19.0"),
+				},
+			},
+			expectedContent: "name: test_app\r\ndependencies:\r\n  flutter:\r\n    sdk: flutter\r\nenvironment:\r\n  sdk: \"2.19.0\"\r\n",
+			expectError:     false,
+		},
+		{
+			name: "missing dependency",
+			initialContent: `name: test_app
+dependencies:
+  http: ^0.13.3
+`,
+			update: &models.Update{
+				DependencyUpdates: []models.DependencyUpdate{
+					{Name: "http", CurrentVersion: "0.13.3", LatestVersion: "1.2.0", Section: models.DevDependenciesSection},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "keep constraint styles",
+			initialContent: `name: test_app
+environment:
+  sdk: ">=3.0.0 <4.0.0"
+  flutter: ^3.10.0
+dependencies:
+  http: '>=0.13.0 <0.14.0'
+  path: 1.8.0
+  provider: ~6.0.0
+`,
+			update: &models.Update{
+				EnvironmentUpdate: &models.EnvironmentUpdate{
+					DartSDKVersion:    createStringPtr("3.5.0"),
+					FlutterSDKVersion: createStringPtr("3.24.0"),
+				},
+				DependencyUpdates: []models.DependencyUpdate{
+					{Name: "http", CurrentVersion: "0.13.0", LatestVersion: "1.2.0", Section: models.DependenciesSection},
+					{Name: "path", CurrentVersion: "1.8.0", LatestVersion: "1.9.0", Section: models.DependenciesSection},
+					{Name: "provider", CurrentVersion: "6.0.0", LatestVersion: "6.0.5", Section: models.DependenciesSection},
+				},
+			},
+			expectedContent: `name: test_app
+environment:
+  sdk: ">=3.5.0 <4.0.0"
+  flutter: ^3.24.0
+dependencies:
+  http: '>=1.2.0 <2.0.0'
+  path: 1.9.0
+  provider: ~6.0.5
+`,
+			expectError: false,
+		},
+		{
+			name: "published and local version of an overridden package",
+			initialContent: `name: test_app
+dependencies:
+  models: ^1.2.0
+dependency_overrides:
+  models:
+    path: ../models
+`,
+			update: &models.Update{
+				DependencyUpdates: []models.DependencyUpdate{
+					{Name: "models", CurrentVersion: "1.2.0", LatestVersion: "1.3.0", Section: models.DependenciesSection},
+					{Name: "models", CurrentVersion: "1.2.0", LatestVersion: "1.4.0", Section: models.DependenciesSection, Source: models.PathSource},
+				},
+			},
+			expectedContent: `name: test_app
+dependencies:
+  models: ^1.3.0
+dependency_overrides:
+  models:
+    path: ../models
+`,
+		},
 		{
 			name:           "nil update",
 			initialContent: `name: test_app`,
@@ -202,6 +461,85 @@ dependencies:
 	}
 }
 
+func TestFileWriterService_PreviewUpdates(t *testing.T) {
+	pubspecPath := filepath.Join(t.TempDir(), "pubspec.yaml")
+	initialContent := "name: test_app\ndependencies:\n  http: ^0.13.3\n"
+	if err := os.WriteFile(pubspecPath, []byte(initialContent), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	writer := NewFileWriterService(pubspecPath)
+	original, updated, err := writer.PreviewUpdates(&models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "http", CurrentVersion: "0.13.3", LatestVersion: "1.2.0", Section: models.DependenciesSection},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, initialContent, string(original))
+	assert.Equal(t, "name: test_app\ndependencies:\n  http: ^1.2.0\n", string(updated))
+
+	// The file itself is left alone
+	content, err := os.ReadFile(pubspecPath)
+	assert.NoError(t, err)
+	assert.Equal(t, initialContent, string(content))
+}
+
+func TestFileWriterService_Backup(t *testing.T) {
+	update := &models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "http", CurrentVersion: "0.13.3", LatestVersion: "1.2.0", Section: models.DependenciesSection},
+		},
+	}
+	initialContent := "name: test_app\ndependencies:\n  http: ^0.13.3\n"
+
+	t.Run("write keeps the file mode and a backup", func(t *testing.T) {
+		dir := t.TempDir()
+		pubspecPath := filepath.Join(dir, "pubspec.yaml")
+		if err := os.WriteFile(pubspecPath, []byte(initialContent), 0600); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+
+		writer := &FileWriterService{PubspecFilePath: pubspecPath, Backup: true}
+		assert.NoError(t, writer.WriteUpdates(update))
+
+		info, err := os.Stat(pubspecPath)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		backup, err := os.ReadFile(pubspecPath + BACKUP_FILE_SUFFIX)
+		assert.NoError(t, err)
+		assert.Equal(t, initialContent, string(backup))
+
+		// No temporary files are left behind
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+
+		assert.NoError(t, writer.RestoreBackup())
+		content, err := os.ReadFile(pubspecPath)
+		assert.NoError(t, err)
+		assert.Equal(t, initialContent, string(content))
+
+		_, err = os.Stat(pubspecPath + BACKUP_FILE_SUFFIX)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("write without backup", func(t *testing.T) {
+		pubspecPath := filepath.Join(t.TempDir(), "pubspec.yaml")
+		if err := os.WriteFile(pubspecPath, []byte(initialContent), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+
+		writer := NewFileWriterService(pubspecPath)
+		assert.NoError(t, writer.WriteUpdates(update))
+
+		_, err := os.Stat(pubspecPath + BACKUP_FILE_SUFFIX)
+		assert.True(t, os.IsNotExist(err))
+		assert.ErrorContains(t, writer.RestoreBackup(), "no backup found")
+	})
+}
+
 // Helper function to create a pointer to a string
 func createStringPtr(s string) *string {
 	return &s
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/models"
+	"github.com/sunderee/puby/internal/semver"
+)
+
+// produceSliceOfGitDependencies returns the git dependencies of every section
+// that pass the include and exclude filters, sorted by name
+func (s *UpdateService) produceSliceOfGitDependencies(pubspec *models.Pubspec) []models.Dependency {
+	var gitDependencies []models.Dependency
+	for _, section := range models.DependencySections {
+		for dependencyName, dependencyValue := range pubspec.DependenciesIn(section) {
+			dependency := models.ParseDependency(dependencyName, section, dependencyValue)
+			if dependency.Source != models.GitSource || dependency.GitURL == "" {
+				continue
+			}
+
+			if s.isPackageSelected(dependencyName) {
+				gitDependencies = append(gitDependencies, dependency)
+			}
+		}
+	}
+
+	sort.SliceStable(gitDependencies, func(i, j int) bool {
+		return gitDependencies[i].Name < gitDependencies[j].Name
+	})
+
+	return gitDependencies
+}
+
+// checkGitDependencies lists the refs of every repository the git dependencies
+// come from, once per repository, and reports the dependencies pinned to a
+// tag for which a newer tag exists. Repositories that can't be listed are
+// reported as failures of the dependencies that use them.
+func (s *UpdateService) checkGitDependencies(ctx context.Context, gitDependencies []models.Dependency) ([]models.DependencyUpdate, []models.PackageFailure) {
+	var repositoryURLs []string
+	seen := make(map[string]bool)
+	for _, dependency := range gitDependencies {
+		if !seen[dependency.GitURL] {
+			seen[dependency.GitURL] = true
+			repositoryURLs = append(repositoryURLs, dependency.GitURL)
+		}
+	}
+
+	refs := make([]*models.GitRefs, len(repositoryURLs))
+	errs := s.lookupConcurrently(ctx, len(repositoryURLs), func(i int) error {
+		var err error
+		refs[i], err = s.GitService.ListRemoteRefs(ctx, repositoryURLs[i])
+		return err
+	})
+
+	refsByURL := make(map[string]*models.GitRefs)
+	errsByURL := make(map[string]error)
+	for i, repositoryURL := range repositoryURLs {
+		refsByURL[repositoryURL] = refs[i]
+		errsByURL[repositoryURL] = errs[i]
+	}
+
+	var dependencyUpdates []models.DependencyUpdate
+	var failures []models.PackageFailure
+	failed := make(map[string]bool)
+	for _, dependency := range gitDependencies {
+		if err := errsByURL[dependency.GitURL]; err != nil {
+			if !failed[dependency.Name] {
+				failed[dependency.Name] = true
+				failures = append(failures, newPackageFailure(dependency.Name, err))
+			}
+			continue
+		}
+
+		if latestTag := s.newerGitTag(dependency.Name, dependency.GitRef, refsByURL[dependency.GitURL]); latestTag != "" {
+			dependencyUpdates = append(dependencyUpdates, models.DependencyUpdate{
+				Name:           dependency.Name,
+				CurrentVersion: dependency.GitRef,
+				LatestVersion:  latestTag,
+				Section:        dependency.Section,
+				Source:         models.GitSource,
+			})
+		}
+	}
+
+	return dependencyUpdates, failures
+}
+
+// newerGitTag returns the newest tag that the configured update policy and the
+// package's rule allow moving a dependency pinned to the ref to. Only refs that
+// name a version, optionally prefixed with "v", are compared, and only against
+// tags written the same way. Refs that name a branch or a commit track
+// something other than releases, so they are never reported. Pre-release tags
+// are only considered if the ref itself is one. It returns an empty string if
+// there is no newer tag.
+func (s *UpdateService) newerGitTag(packageName, ref string, refs *models.GitRefs) string {
+	if ref == "" || refs == nil {
+		return ""
+	}
+	for _, branch := range refs.Branches {
+		if branch == ref {
+			return ""
+		}
+	}
+
+	prefix := ""
+	if strings.HasPrefix(ref, "v") {
+		prefix = "v"
+	}
+	current, err := semver.Parse(strings.TrimPrefix(ref, prefix))
+	if err != nil {
+		return ""
+	}
+
+	policy := config.UpdatePolicyMajor
+	if s.Config != nil && s.Config.UpdatePolicy != nil {
+		policy = *s.Config.UpdatePolicy
+	}
+	pinned := semver.Constraint{Min: &current, IncludeMin: true, Max: &current, IncludeMax: true}
+
+	latestTag := ""
+	latest := current
+	for _, tag := range refs.Tags {
+		versionText, ok := strings.CutPrefix(tag, prefix)
+		if !ok {
+			continue
+		}
+
+		version, err := semver.Parse(versionText)
+		if err != nil || !version.GreaterThan(latest) {
+			continue
+		}
+		if version.IsPreRelease() && !current.IsPreRelease() {
+			continue
+		}
+		if !isAllowedByPolicy(policy, pinned, version) || !s.packageRule(packageName).Allows(version) {
+			continue
+		}
+
+		latestTag = tag
+		latest = version
+	}
+
+	return latestTag
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestUpdateService_NewerGitTag(t *testing.T) {
+	refs := &models.GitRefs{
+		Tags:     []string{"v1.0.0", "v1.0.1", "v1.1.0", "v2.0.0", "v3.0.0-beta.1", "1.5.0", "nightly"},
+		Branches: []string{"main", "release"},
+	}
+
+	tests := []struct {
+		name     string
+		ref      string
+		policy   *config.UpdatePolicy
+		expected string
+	}{
+		{name: "newest tag", ref: "v1.0.0", expected: "v2.0.0"},
+		{name: "already newest", ref: "v2.0.0", expected: ""},
+		{name: "tags without prefix", ref: "1.0.0", expected: "1.5.0"},
+		{name: "pre-release ref", ref: "v2.0.0", expected: ""},
+		{name: "pre-release tags for pre-release ref", ref: "v3.0.0-alpha", expected: "v3.0.0-beta.1"},
+		{name: "branch", ref: "main", expected: ""},
+		{name: "commit", ref: "a1b2c3d", expected: ""},
+		{name: "no ref", ref: "", expected: ""},
+		{name: "patch policy", ref: "v1.0.0", policy: updatePolicyPtr(config.UpdatePolicyPatch), expected: "v1.0.1"},
+		{name: "minor policy", ref: "v1.0.0", policy: updatePolicyPtr(config.UpdatePolicyMinor), expected: "v1.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &UpdateService{Config: &config.CLIConfig{UpdatePolicy: tt.policy}}
+			assert.Equal(t, tt.expected, service.newerGitTag("forked", tt.ref, refs))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+const DEFAULT_GIT_BINARY = "git"
+
+// GitService queries git repositories using the local git binary
+type GitService struct {
+	GitBinary string
+}
+
+// NewGitService creates a new instance of GitService
+func NewGitService() GitServiceInterface {
+	return &GitService{
+		GitBinary: DEFAULT_GIT_BINARY,
+	}
+}
+
+// ListRemoteRefs lists the tags and branches of a remote repository without
+// cloning it. Git is not allowed to prompt for credentials, so repositories
+// that require them fail instead of blocking the check, and the command is
+// stopped once the context is cancelled. URLs starting with a dash are
+// rejected, as git would take them for an option.
+func (s *GitService) ListRemoteRefs(ctx context.Context, repositoryURL string) (*models.GitRefs, error) {
+	if strings.HasPrefix(repositoryURL, "-") {
+		return nil, fmt.Errorf("invalid repository URL %q", repositoryURL)
+	}
+
+	cmd := exec.CommandContext(ctx, s.GitBinary, "ls-remote", "--tags", "--heads", "--", repositoryURL)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, fmt.Errorf("git ls-remote failed: %s", message)
+	}
+
+	return parseRemoteRefs(stdout.String()), nil
+}
+
+// parseRemoteRefs extracts tag and branch names from the output of
+// git ls-remote. The peeled entries of annotated tags ("^{}") are skipped, as
+// they repeat the tag name.
+func parseRemoteRefs(output string) *models.GitRefs {
+	refs := &models.GitRefs{}
+	for _, line := range strings.Split(output, "\n") {
+		_, ref, found := strings.Cut(strings.TrimSpace(line), "\t")
+		if !found || strings.HasSuffix(ref, "^{}") {
+			continue
+		}
+
+		if tag, ok := strings.CutPrefix(ref, "refs/tags/"); ok {
+			refs.Tags = append(refs.Tags, tag)
+		} else if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			refs.Branches = append(refs.Branches, branch)
+		}
+	}
+
+	return refs
+}
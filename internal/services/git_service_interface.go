@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+// GitServiceInterface defines the interface for querying git repositories
+type GitServiceInterface interface {
+	ListRemoteRefs(ctx context.Context, repositoryURL string) (*models.GitRefs, error)
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestGitService_ListRemoteRefs(t *testing.T) {
+	if _, err := exec.LookPath(DEFAULT_GIT_BINARY); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// Set up a bare repository with a branch, a lightweight and an annotated tag
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	work := filepath.Join(dir, "work")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command(DEFAULT_GIT_BINARY, append([]string{"-c", "user.name=puby", "-c", "user.email=puby@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	git("init", "--bare", remote)
+	git("init", "-b", "main", work)
+	git("-C", work, "commit", "--allow-empty", "-m", "initial")
+	git("-C", work, "tag", "v1.0.0")
+	git("-C", work, "tag", "-a", "v1.1.0", "-m", "release 1.1.0")
+	git("-C", work, "push", "--tags", remote, "main")
+
+	service := NewGitService()
+
+	t.Run("tags and branches are listed", func(t *testing.T) {
+		refs, err := service.ListRemoteRefs(context.Background(), remote)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.GitRefs{Tags: []string{"v1.0.0", "v1.1.0"}, Branches: []string{"main"}}, refs)
+	})
+
+	t.Run("missing repository", func(t *testing.T) {
+		refs, err := service.ListRemoteRefs(context.Background(), filepath.Join(dir, "missing.git"))
+		assert.Error(t, err)
+		assert.Nil(t, refs)
+	})
+
+	t.Run("URL that looks like an option", func(t *testing.T) {
+		marker := filepath.Join(dir, "marker")
+		refs, err := service.ListRemoteRefs(context.Background(), "--upload-pack=touch "+marker)
+		assert.ErrorContains(t, err, "invalid repository URL")
+		assert.Nil(t, refs)
+		assert.NoFileExists(t, marker)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		refs, err := service.ListRemoteRefs(ctx, remote)
+		assert.Error(t, err)
+		assert.Nil(t, refs)
+	})
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+// JSON_SCHEMA_VERSION is the version of the JSON output. It is increased
+// whenever a field is removed or its meaning changes; new fields may be added
+// without changing it.
+const JSON_SCHEMA_VERSION = 1
+
+// JSONDisplayService prints updates as a JSON document for scripts and
+// dashboards
+type JSONDisplayService struct {
+	Output io.Writer
+}
+
+// NewJSONDisplayService creates a new instance of JSONDisplayService that
+// prints to stdout
+func NewJSONDisplayService() DisplayServiceInterface {
+	return &JSONDisplayService{
+		Output: os.Stdout,
+	}
+}
+
+// jsonReport is the top-level JSON document for a single project
+type jsonReport struct {
+	SchemaVersion int `json:"schema_version"`
+	jsonUpdate
+}
+
+// jsonWorkspaceReport is the top-level JSON document for several projects
+type jsonWorkspaceReport struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Root          string               `json:"root"`
+	Projects      []jsonProjectReport  `json:"projects"`
+	Summary       jsonWorkspaceSummary `json:"summary"`
+}
+
+// jsonProjectReport holds either the updates of a project or the error that
+// prevented checking it
+type jsonProjectReport struct {
+	Pubspec string `json:"pubspec"`
+	Error   string `json:"error,omitempty"`
+	*jsonUpdate
+}
+
+// jsonWorkspaceSummary aggregates the results of all projects
+type jsonWorkspaceSummary struct {
+	Projects            int            `json:"projects"`
+	ProjectsWithUpdates int            `json:"projects_with_updates"`
+	ProjectsWithErrors  int            `json:"projects_with_errors"`
+	Updates             int            `json:"updates"`
+	UpdatesByKind       map[string]int `json:"updates_by_kind"`
+	Failures            int            `json:"failures"`
+}
+
+// jsonUpdate holds the updates of a single project
+type jsonUpdate struct {
+	SDKUpdates        []jsonSDKUpdate        `json:"sdk_updates"`
+	DependencyUpdates []jsonDependencyUpdate `json:"dependency_updates"`
+	TransitiveUpdates []jsonDependencyUpdate `json:"transitive_updates"`
+	HeldBack          []jsonHeldBackUpdate   `json:"held_back"`
+	Failures          []jsonFailure          `json:"failures"`
+	Unknown           []string               `json:"unknown"`
+	SDKStatusUnknown  bool                   `json:"sdk_status_unknown"`
+}
+
+// jsonSDKUpdate is the latest version of an SDK that is newer than the one
+// the pubspec requires
+type jsonSDKUpdate struct {
+	SDK      string     `json:"sdk"`
+	Latest   string     `json:"latest"`
+	CachedAt *time.Time `json:"cached_at,omitempty"`
+}
+
+// jsonDependencyUpdate is a single dependency update
+type jsonDependencyUpdate struct {
+	Name       string     `json:"name"`
+	Section    string     `json:"section,omitempty"`
+	Source     string     `json:"source"`
+	Constraint string     `json:"constraint,omitempty"`
+	Current    string     `json:"current"`
+	Resolved   string     `json:"resolved,omitempty"`
+	Latest     string     `json:"latest"`
+	Kind       string     `json:"kind"`
+	HostedURL  string     `json:"hosted_url,omitempty"`
+	CachedAt   *time.Time `json:"cached_at,omitempty"`
+}
+
+// jsonHeldBackUpdate is a dependency update that an annotation in
+// pubspec.yaml holds back
+type jsonHeldBackUpdate struct {
+	jsonDependencyUpdate
+	Reason string `json:"reason"`
+}
+
+// jsonFailure is a package that could not be checked
+type jsonFailure struct {
+	Name       string `json:"name"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error"`
+}
+
+// PrintUpdate implements the DisplayServiceInterface
+func (s *JSONDisplayService) PrintUpdate(update *models.Update) {
+	s.encode(jsonReport{
+		SchemaVersion: JSON_SCHEMA_VERSION,
+		jsonUpdate:    newJSONUpdate(update),
+	})
+}
+
+// PrintWorkspaceUpdate implements the DisplayServiceInterface
+func (s *JSONDisplayService) PrintWorkspaceUpdate(workspaceUpdate *models.WorkspaceUpdate) {
+	summary := workspaceUpdate.Summary()
+	report := jsonWorkspaceReport{
+		SchemaVersion: JSON_SCHEMA_VERSION,
+		Root:          workspaceUpdate.RootDir,
+		Projects:      []jsonProjectReport{},
+		Summary: jsonWorkspaceSummary{
+			Projects:            summary.Projects,
+			ProjectsWithUpdates: summary.ProjectsWithUpdates,
+			ProjectsWithErrors:  summary.ProjectsWithErrors,
+			Updates:             summary.Updates,
+			UpdatesByKind:       make(map[string]int),
+			Failures:            summary.Failures,
+		},
+	}
+
+	for kind, count := range summary.UpdatesByKind {
+		report.Summary.UpdatesByKind[string(kind)] = count
+	}
+
+	for _, project := range workspaceUpdate.Projects {
+		projectReport := jsonProjectReport{Pubspec: project.PubspecPath}
+		if project.Err != nil {
+			projectReport.Error = project.Err.Error()
+		} else {
+			update := newJSONUpdate(project.Update)
+			projectReport.jsonUpdate = &update
+		}
+		report.Projects = append(report.Projects, projectReport)
+	}
+
+	s.encode(report)
+}
+
+// encode prints the document as indented JSON
+func (s *JSONDisplayService) encode(document any) {
+	encoder := json.NewEncoder(s.Output)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON output: %v\n", err)
+	}
+}
+
+// newJSONUpdate converts an update for the JSON document. Lists are always
+// present, even when empty, so consumers don't have to handle null.
+func newJSONUpdate(update *models.Update) jsonUpdate {
+	if update == nil {
+		update = &models.Update{}
+	}
+
+	report := jsonUpdate{
+		SDKUpdates:        []jsonSDKUpdate{},
+		DependencyUpdates: []jsonDependencyUpdate{},
+		TransitiveUpdates: []jsonDependencyUpdate{},
+		HeldBack:          []jsonHeldBackUpdate{},
+		Failures:          []jsonFailure{},
+		Unknown:           []string{},
+		SDKStatusUnknown:  update.SDKStatusUnknown,
+	}
+
+	if env := update.EnvironmentUpdate; env != nil {
+		cachedAt := optionalTime(env.CachedAt)
+		if env.DartSDKVersion != nil {
+			report.SDKUpdates = append(report.SDKUpdates, jsonSDKUpdate{SDK: "dart", Latest: *env.DartSDKVersion, CachedAt: cachedAt})
+		}
+		if env.FlutterSDKVersion != nil {
+			report.SDKUpdates = append(report.SDKUpdates, jsonSDKUpdate{SDK: "flutter", Latest: *env.FlutterSDKVersion, CachedAt: cachedAt})
+		}
+	}
+
+	for _, dep := range update.DependencyUpdates {
+		section := dep.Section
+		if section == "" {
+			section = models.DependenciesSection
+		}
+		report.DependencyUpdates = append(report.DependencyUpdates, newJSONDependencyUpdate(dep, string(section)))
+	}
+	for _, dep := range update.TransitiveUpdates {
+		report.TransitiveUpdates = append(report.TransitiveUpdates, newJSONDependencyUpdate(dep, ""))
+	}
+	for _, dep := range update.HeldBack {
+		section := dep.Section
+		if section == "" {
+			section = models.DependenciesSection
+		}
+		report.HeldBack = append(report.HeldBack, jsonHeldBackUpdate{
+			jsonDependencyUpdate: newJSONDependencyUpdate(dep.DependencyUpdate, string(section)),
+			Reason:               dep.Reason,
+		})
+	}
+
+	for _, failure := range update.Failures {
+		message := "unknown error"
+		if failure.Cause != nil {
+			message = failure.Cause.Error()
+		}
+		report.Failures = append(report.Failures, jsonFailure{Name: failure.Name, StatusCode: failure.StatusCode, Error: message})
+	}
+
+	report.Unknown = append(report.Unknown, update.Unknown...)
+
+	return report
+}
+
+// newJSONDependencyUpdate converts a dependency update for the JSON document
+func newJSONDependencyUpdate(dep models.DependencyUpdate, section string) jsonDependencyUpdate {
+	source := dep.Source
+	if source == "" {
+		source = models.HostedSource
+	}
+
+	return jsonDependencyUpdate{
+		Name:       dep.Name,
+		Section:    section,
+		Source:     string(source),
+		Constraint: dep.Constraint,
+		Current:    dep.CurrentVersion,
+		Resolved:   dep.ResolvedVersion,
+		Latest:     dep.LatestVersion,
+		Kind:       string(dep.Kind()),
+		HostedURL:  dep.HostedURL,
+		CachedAt:   optionalTime(dep.CachedAt),
+	}
+}
+
+// optionalTime returns nil for the zero time, so it is left out of the output
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestJSONDisplayService_PrintUpdate(t *testing.T) {
+	printJSON := func(t *testing.T, update *models.Update) map[string]any {
+		var output bytes.Buffer
+		displayService := &JSONDisplayService{Output: &output}
+		displayService.PrintUpdate(update)
+
+		var report map[string]any
+		require.NoError(t, json.Unmarshal(output.Bytes(), &report))
+		return report
+	}
+
+	t.Run("No updates", func(t *testing.T) {
+		report := printJSON(t, nil)
+
+		assert.Equal(t, float64(JSON_SCHEMA_VERSION), report["schema_version"])
+		assert.Equal(t, []any{}, report["sdk_updates"])
+		assert.Equal(t, []any{}, report["dependency_updates"])
+		assert.Equal(t, []any{}, report["transitive_updates"])
+		assert.Equal(t, []any{}, report["held_back"])
+		assert.Equal(t, []any{}, report["failures"])
+		assert.Equal(t, []any{}, report["unknown"])
+		assert.Equal(t, false, report["sdk_status_unknown"])
+	})
+
+	t.Run("Updates and failures", func(t *testing.T) {
+		dartVersion := "3.5.0"
+		update := &models.Update{
+			EnvironmentUpdate: &models.EnvironmentUpdate{
+				DartSDKVersion: &dartVersion,
+			},
+			DependencyUpdates: []models.DependencyUpdate{
+				{
+					Name:            "http",
+					CurrentVersion:  "1.1.0",
+					LatestVersion:   "2.0.0",
+					Constraint:      "^1.1.0",
+					ResolvedVersion: "1.1.2",
+				},
+				{
+					Name:           "forked",
+					CurrentVersion: "v1.0.0",
+					LatestVersion:  "v1.1.0",
+					Section:        models.DevDependenciesSection,
+					Source:         models.GitSource,
+				},
+			},
+			Failures: []models.PackageFailure{
+				{Name: "missing", StatusCode: 404, Cause: errors.New("not found")},
+			},
+			HeldBack: []models.HeldBackUpdate{
+				{
+					DependencyUpdate: models.DependencyUpdate{Name: "intl", CurrentVersion: "0.18.0", LatestVersion: "0.20.2", Constraint: "^0.18.0"},
+					Reason:           "max 0.19",
+				},
+			},
+			Unknown: []string{"offline_only"},
+		}
+
+		report := printJSON(t, update)
+
+		assert.Equal(t, []any{
+			map[string]any{"sdk": "dart", "latest": "3.5.0"},
+		}, report["sdk_updates"])
+		assert.Equal(t, []any{
+			map[string]any{
+				"name":       "http",
+				"section":    "dependencies",
+				"source":     "hosted",
+				"constraint": "^1.1.0",
+				"current":    "1.1.0",
+				"resolved":   "1.1.2",
+				"latest":     "2.0.0",
+				"kind":       "major",
+			},
+			map[string]any{
+				"name":    "forked",
+				"section": "dev_dependencies",
+				"source":  "git",
+				"current": "v1.0.0",
+				"latest":  "v1.1.0",
+				"kind":    "minor",
+			},
+		}, report["dependency_updates"])
+		assert.Equal(t, []any{
+			map[string]any{
+				"name":       "intl",
+				"section":    "dependencies",
+				"source":     "hosted",
+				"constraint": "^0.18.0",
+				"current":    "0.18.0",
+				"latest":     "0.20.2",
+				"kind":       "major",
+				"reason":     "max 0.19",
+			},
+		}, report["held_back"])
+		assert.Equal(t, []any{
+			map[string]any{"name": "missing", "status_code": float64(404), "error": "not found"},
+		}, report["failures"])
+		assert.Equal(t, []any{"offline_only"}, report["unknown"])
+	})
+}
+
+func TestJSONDisplayService_PrintWorkspaceUpdate(t *testing.T) {
+	var output bytes.Buffer
+	displayService := &JSONDisplayService{Output: &output}
+	displayService.PrintWorkspaceUpdate(&models.WorkspaceUpdate{
+		RootDir: "/repo",
+		Projects: []models.ProjectUpdate{
+			{
+				PubspecPath: "apps/mobile/pubspec.yaml",
+				Update: &models.Update{
+					DependencyUpdates: []models.DependencyUpdate{
+						{Name: "http", CurrentVersion: "1.2.0", LatestVersion: "2.0.0"},
+					},
+				},
+			},
+			{
+				PubspecPath: "packages/broken/pubspec.yaml",
+				Err:         errors.New("failed to parse pubspec.yaml"),
+			},
+		},
+	})
+
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(output.Bytes(), &report))
+
+	assert.Equal(t, float64(JSON_SCHEMA_VERSION), report["schema_version"])
+	assert.Equal(t, "/repo", report["root"])
+
+	projects := report["projects"].([]any)
+	require.Len(t, projects, 2)
+
+	mobile := projects[0].(map[string]any)
+	assert.Equal(t, "apps/mobile/pubspec.yaml", mobile["pubspec"])
+	assert.Len(t, mobile["dependency_updates"], 1)
+	assert.NotContains(t, mobile, "error")
+
+	broken := projects[1].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"pubspec": "packages/broken/pubspec.yaml",
+		"error":   "failed to parse pubspec.yaml",
+	}, broken)
+
+	assert.Equal(t, map[string]any{
+		"projects":              float64(2),
+		"projects_with_updates": float64(1),
+		"projects_with_errors":  float64(1),
+		"updates":               float64(1),
+		"updates_by_kind":       map[string]any{"major": float64(1)},
+		"failures":              float64(0),
+	}, report["summary"])
+}
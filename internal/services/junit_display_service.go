@@ -0,0 +1,255 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+// JUnitDisplayService prints updates as a JUnit XML report with one test case
+// per dependency, so CI systems can show outdated dependencies as failed tests
+type JUnitDisplayService struct {
+	Output io.Writer
+}
+
+// NewJUnitDisplayService creates a new instance of JUnitDisplayService that
+// prints to stdout
+func NewJUnitDisplayService() DisplayServiceInterface {
+	return &JUnitDisplayService{
+		Output: os.Stdout,
+	}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitProblem `xml:"skipped,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+}
+
+// PrintUpdate implements the DisplayServiceInterface
+func (s *JUnitDisplayService) PrintUpdate(update *models.Update) {
+	s.encode(newJUnitReport(newJUnitTestSuites(update, "")))
+}
+
+// PrintWorkspaceUpdate implements the DisplayServiceInterface. The test suites
+// of every project are prefixed with the path of its pubspec.yaml, and projects
+// that could not be checked are reported as a test suite with an error.
+func (s *JUnitDisplayService) PrintWorkspaceUpdate(workspaceUpdate *models.WorkspaceUpdate) {
+	var suites []junitTestSuite
+	for _, project := range workspaceUpdate.Projects {
+		if project.Err != nil {
+			suites = append(suites, junitTestSuite{
+				Name: project.PubspecPath,
+				TestCases: []junitTestCase{
+					{Name: "check", ClassName: project.PubspecPath, Error: &junitProblem{Message: project.Err.Error()}},
+				},
+			})
+			continue
+		}
+
+		suites = append(suites, newJUnitTestSuites(project.Update, project.PubspecPath+": ")...)
+	}
+
+	s.encode(newJUnitReport(suites))
+}
+
+// encode prints the report as indented XML
+func (s *JUnitDisplayService) encode(report junitTestSuites) {
+	if _, err := io.WriteString(s.Output, xml.Header); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing JUnit output: %v\n", err)
+		return
+	}
+
+	encoder := xml.NewEncoder(s.Output)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JUnit output: %v\n", err)
+		return
+	}
+	fmt.Fprintln(s.Output)
+}
+
+// newJUnitTestSuites converts an update into test suites named with the given
+// prefix. There is a test suite for the SDKs, one for every dependency section
+// and one for transitive dependencies.
+func newJUnitTestSuites(update *models.Update, prefix string) []junitTestSuite {
+	if update == nil {
+		update = &models.Update{}
+	}
+
+	var suites []junitTestSuite
+
+	// SDKs are reported as failed when an update is available, and skipped
+	// when their status is unknown
+	environmentSuite := junitTestSuite{Name: "environment"}
+	if env := update.EnvironmentUpdate; env != nil {
+		if env.DartSDKVersion != nil {
+			environmentSuite.TestCases = append(environmentSuite.TestCases, junitTestCase{
+				Name:      "dart",
+				ClassName: "environment",
+				Failure:   &junitProblem{Message: fmt.Sprintf("Dart SDK %s is available", *env.DartSDKVersion), Type: "sdk"},
+			})
+		}
+		if env.FlutterSDKVersion != nil {
+			environmentSuite.TestCases = append(environmentSuite.TestCases, junitTestCase{
+				Name:      "flutter",
+				ClassName: "environment",
+				Failure:   &junitProblem{Message: fmt.Sprintf("Flutter SDK %s is available", *env.FlutterSDKVersion), Type: "sdk"},
+			})
+		}
+	}
+	if update.SDKStatusUnknown {
+		environmentSuite.TestCases = append(environmentSuite.TestCases, junitTestCase{
+			Name:      "sdk",
+			ClassName: "environment",
+			Skipped:   &junitProblem{Message: "not cached"},
+		})
+	}
+	suites = append(suites, environmentSuite)
+
+	// Every checked dependency is a test case, which fails if it has an update
+	updatesByKey := make(map[string]models.DependencyUpdate)
+	for _, dep := range update.DependencyUpdates {
+		updatesByKey[junitKey(dep.Section, dep.Name)] = dep
+	}
+
+	failuresByName := make(map[string]models.PackageFailure)
+	for _, failure := range update.Failures {
+		failuresByName[failure.Name] = failure
+	}
+
+	unknown := make(map[string]bool)
+	for _, name := range update.Unknown {
+		unknown[name] = true
+	}
+
+	for _, section := range models.DependencySections {
+		suite := junitTestSuite{Name: string(section)}
+		reported := make(map[string]bool)
+
+		addTestCase := func(name string) {
+			if reported[name] {
+				return
+			}
+			reported[name] = true
+
+			testCase := junitTestCase{Name: name, ClassName: string(section)}
+			if dep, ok := updatesByKey[junitKey(section, name)]; ok {
+				testCase.Failure = newJUnitUpdateFailure(dep)
+			} else if failure, ok := failuresByName[name]; ok {
+				testCase.Error = &junitProblem{Message: failureReason(failure)}
+			} else if unknown[name] {
+				testCase.Skipped = &junitProblem{Message: "not cached"}
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		for _, dep := range update.Checked {
+			if dep.Section == section {
+				addTestCase(dep.Name)
+			}
+		}
+		for _, dep := range update.DependencyUpdates {
+			if dep.Section == section || (dep.Section == "" && section == models.DependenciesSection) {
+				addTestCase(dep.Name)
+			}
+		}
+
+		suites = append(suites, suite)
+	}
+
+	// Transitive dependencies are only known when they have an update
+	transitiveSuite := junitTestSuite{Name: "transitive"}
+	for _, dep := range update.TransitiveUpdates {
+		transitiveSuite.TestCases = append(transitiveSuite.TestCases, junitTestCase{
+			Name:      dep.Name,
+			ClassName: "transitive",
+			Failure:   newJUnitUpdateFailure(dep),
+		})
+	}
+	suites = append(suites, transitiveSuite)
+
+	for i := range suites {
+		suites[i].Name = prefix + suites[i].Name
+	}
+
+	return suites
+}
+
+// newJUnitReport counts the test results of the suites and combines them into
+// the report. Suites without test cases are left out.
+func newJUnitReport(suites []junitTestSuite) junitTestSuites {
+	report := junitTestSuites{Name: "puby"}
+	for _, suite := range suites {
+		if len(suite.TestCases) == 0 {
+			continue
+		}
+
+		for _, testCase := range suite.TestCases {
+			suite.Tests++
+			switch {
+			case testCase.Failure != nil:
+				suite.Failures++
+			case testCase.Error != nil:
+				suite.Errors++
+			case testCase.Skipped != nil:
+				suite.Skipped++
+			}
+		}
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+	}
+
+	return report
+}
+
+// newJUnitUpdateFailure describes a dependency update as a test failure, typed
+// by the kind of update
+func newJUnitUpdateFailure(dep models.DependencyUpdate) *junitProblem {
+	return &junitProblem{
+		Message: fmt.Sprintf("%s can be updated from %s to %s", dep.Name, dep.CurrentVersion, dep.LatestVersion),
+		Type:    string(dep.Kind()),
+	}
+}
+
+// junitKey identifies a dependency within its section. Updates without a
+// section are treated as regular dependencies.
+func junitKey(section models.DependencySection, name string) string {
+	if section == "" {
+		section = models.DependenciesSection
+	}
+	return string(section) + " " + name
+}
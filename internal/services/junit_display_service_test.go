@@ -0,0 +1,82 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestJUnitDisplayService_PrintUpdate(t *testing.T) {
+	update := &models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "2.0.0", Section: models.DependenciesSection},
+		},
+		TransitiveUpdates: []models.DependencyUpdate{
+			{Name: "meta", CurrentVersion: "1.9.0", LatestVersion: "1.10.0"},
+		},
+		Checked: []models.Dependency{
+			{Name: "http", Section: models.DependenciesSection},
+			{Name: "path", Section: models.DependenciesSection},
+			{Name: "private", Section: models.DependenciesSection},
+			{Name: "lints", Section: models.DevDependenciesSection},
+		},
+		Failures: []models.PackageFailure{
+			{Name: "private", Cause: errors.New("connection refused")},
+		},
+		Unknown: []string{"lints"},
+	}
+
+	var output bytes.Buffer
+	displayService := &JUnitDisplayService{Output: &output}
+	displayService.PrintUpdate(update)
+
+	assert.True(t, strings.HasPrefix(output.String(), xml.Header))
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(output.Bytes(), &report))
+
+	assert.Equal(t, 5, report.Tests)
+	assert.Equal(t, 2, report.Failures)
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, 1, report.Skipped)
+
+	require.Len(t, report.Suites, 3)
+	assert.Equal(t, "dependencies", report.Suites[0].Name)
+	assert.Equal(t, "dev_dependencies", report.Suites[1].Name)
+	assert.Equal(t, "transitive", report.Suites[2].Name)
+
+	dependencies := report.Suites[0].TestCases
+	require.Len(t, dependencies, 3)
+	assert.Equal(t, "http", dependencies[0].Name)
+	assert.Equal(t, &junitProblem{Message: "http can be updated from 1.1.0 to 2.0.0", Type: "major"}, dependencies[0].Failure)
+	assert.Equal(t, "path", dependencies[1].Name)
+	assert.Nil(t, dependencies[1].Failure)
+	assert.Nil(t, dependencies[1].Error)
+	assert.Equal(t, &junitProblem{Message: "connection refused"}, dependencies[2].Error)
+
+	assert.Equal(t, &junitProblem{Message: "not cached"}, report.Suites[1].TestCases[0].Skipped)
+	assert.Equal(t, "minor", report.Suites[2].TestCases[0].Failure.Type)
+}
+
+func TestJUnitDisplayService_PrintUpdate_SDK(t *testing.T) {
+	flutterVersion := "3.24.0"
+
+	var output bytes.Buffer
+	displayService := &JUnitDisplayService{Output: &output}
+	displayService.PrintUpdate(&models.Update{
+		EnvironmentUpdate: &models.EnvironmentUpdate{FlutterSDKVersion: &flutterVersion},
+	})
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(output.Bytes(), &report))
+
+	require.Len(t, report.Suites, 1)
+	assert.Equal(t, "environment", report.Suites[0].Name)
+	assert.Equal(t, &junitProblem{Message: "Flutter SDK 3.24.0 is available", Type: "sdk"}, report.Suites[0].TestCases[0].Failure)
+}
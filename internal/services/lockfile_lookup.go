@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+// loadLockfile parses pubspec.lock. A missing lockfile is not an error and
+// results in nil, as does not having a lockfile parser.
+func (s *UpdateService) loadLockfile() (*models.Lockfile, error) {
+	if s.LockfileParser == nil {
+		return nil, nil
+	}
+
+	lockfile, err := s.LockfileParser.Parse()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pubspec.lock: %v", err)
+	}
+
+	return lockfile, nil
+}
+
+// isTransitiveCheckEnabled reports whether indirect dependencies are checked
+func (s *UpdateService) isTransitiveCheckEnabled() bool {
+	return s.Config != nil && s.Config.IncludeTransitive != nil && *s.Config.IncludeTransitive
+}
+
+// produceSliceOfTransitiveDependencies returns the hosted packages the lockfile
+// lists as transitive that pass the include and exclude filters, sorted by
+// name. Their constraint is the resolved version as a lower bound, so they are
+// compared against what is actually used.
+func (s *UpdateService) produceSliceOfTransitiveDependencies(lockfile *models.Lockfile) []models.Dependency {
+	if lockfile == nil {
+		return nil
+	}
+
+	var transitiveDependencies []models.Dependency
+	for packageName, lockedPackage := range lockfile.Packages {
+		if !lockedPackage.IsTransitive() || lockedPackage.Source != string(models.HostedSource) || lockedPackage.Version == "" {
+			continue
+		}
+		if !s.isPackageSelected(packageName) {
+			continue
+		}
+
+		transitiveDependencies = append(transitiveDependencies, models.Dependency{
+			Name:       packageName,
+			Source:     models.HostedSource,
+			Constraint: ">=" + lockedPackage.Version,
+			HostedURL:  lockedPackage.HostedURL(),
+		})
+	}
+
+	sort.Slice(transitiveDependencies, func(i, j int) bool {
+		return transitiveDependencies[i].Name < transitiveDependencies[j].Name
+	})
+
+	return transitiveDependencies
+}
+
+// produceSliceOfTransitiveUpdates compares the transitive dependencies with the
+// data fetched for them. The data slice is index-aligned with the dependencies
+// and holds nil for packages that could not be fetched.
+func (s *UpdateService) produceSliceOfTransitiveUpdates(transitiveDependencies []models.Dependency, packageData []*models.PackageWrapper) []models.DependencyUpdate {
+	var transitiveUpdates []models.DependencyUpdate
+	for i, dependency := range transitiveDependencies {
+		if packageData[i] == nil {
+			continue
+		}
+
+		latestVersion := s.versionToUpdateTo(dependency.Constraint, packageData[i], models.DependencyAnnotation{})
+		if latestVersion == "" {
+			continue
+		}
+
+		if resolvedVersion, isOutdated := compareWithConstraint(dependency.Constraint, latestVersion); isOutdated {
+			transitiveUpdate := models.DependencyUpdate{
+				Name:            dependency.Name,
+				CurrentVersion:  resolvedVersion,
+				LatestVersion:   latestVersion,
+				ResolvedVersion: resolvedVersion,
+				HostedURL:       dependency.HostedURL,
+			}
+			if s.isOffline() {
+				transitiveUpdate.CachedAt = packageData[i].FetchedAt
+			}
+
+			transitiveUpdates = append(transitiveUpdates, transitiveUpdate)
+		}
+	}
+
+	return transitiveUpdates
+}
+
+// applyResolvedVersions records the version pubspec.lock resolved each hosted
+// dependency update to
+func applyResolvedVersions(dependencyUpdates []models.DependencyUpdate, lockfile *models.Lockfile) {
+	if lockfile == nil {
+		return
+	}
+
+	for i, dependencyUpdate := range dependencyUpdates {
+		if dependencyUpdate.Source != "" && dependencyUpdate.Source != models.HostedSource {
+			continue
+		}
+
+		if lockedPackage, ok := lockfile.Packages[dependencyUpdate.Name]; ok && lockedPackage.Source == string(models.HostedSource) {
+			dependencyUpdates[i].ResolvedVersion = lockedPackage.Version
+		}
+	}
+}
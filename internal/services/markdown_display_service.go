@@ -0,0 +1,208 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+const PUB_DEV_PACKAGE_PAGE_URL = "https://pub.dev/packages/%s"
+
+// MarkdownDisplayService prints updates as Markdown tables that can be pasted
+// into pull request descriptions
+type MarkdownDisplayService struct {
+	Output io.Writer
+
+	// DefaultHostedURL is the package repository that packages without a
+	// hosted URL come from. It is pub.dev if empty. Only packages from pub.dev
+	// link to their page.
+	DefaultHostedURL string
+}
+
+// NewMarkdownDisplayService creates a new instance of MarkdownDisplayService
+// that prints to stdout
+func NewMarkdownDisplayService() DisplayServiceInterface {
+	return &MarkdownDisplayService{
+		Output:           os.Stdout,
+		DefaultHostedURL: DefaultHostedURL(),
+	}
+}
+
+// PrintUpdate implements the DisplayServiceInterface
+func (s *MarkdownDisplayService) PrintUpdate(update *models.Update) {
+	printMarkdownUpdate(s.Output, update, s.DefaultHostedURL)
+}
+
+// PrintWorkspaceUpdate implements the DisplayServiceInterface
+func (s *MarkdownDisplayService) PrintWorkspaceUpdate(workspaceUpdate *models.WorkspaceUpdate) {
+	out := s.Output
+
+	for _, project := range workspaceUpdate.Projects {
+		fmt.Fprintf(out, "## `%s`\n", project.PubspecPath)
+		fmt.Fprintln(out)
+		if project.Err != nil {
+			fmt.Fprintf(out, "Could not be checked: %v\n", project.Err)
+			fmt.Fprintln(out)
+			continue
+		}
+
+		// Tables end with a blank line already, the up to date message does not
+		var output strings.Builder
+		printMarkdownUpdate(&output, project.Update, s.DefaultHostedURL)
+		fmt.Fprint(out, output.String())
+		if !strings.HasSuffix(output.String(), "\n\n") {
+			fmt.Fprintln(out)
+		}
+	}
+
+	summary := workspaceUpdate.Summary()
+	fmt.Fprintln(out, "## Summary")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "| | Count |")
+	fmt.Fprintln(out, "|-|-------|")
+	fmt.Fprintf(out, "| Projects checked | %d |\n", summary.Projects)
+	fmt.Fprintf(out, "| Projects with updates | %d |\n", summary.ProjectsWithUpdates)
+	fmt.Fprintf(out, "| Updates | %d |\n", summary.Updates)
+	for _, kind := range []models.UpdateKind{models.UpdateKindMajor, models.UpdateKindMinor, models.UpdateKindPatch} {
+		if count := summary.UpdatesByKind[kind]; count > 0 {
+			fmt.Fprintf(out, "| %s updates | %d |\n", kind, count)
+		}
+	}
+	if summary.Failures > 0 {
+		fmt.Fprintf(out, "| Packages that could not be checked | %d |\n", summary.Failures)
+	}
+	if summary.ProjectsWithErrors > 0 {
+		fmt.Fprintf(out, "| Projects with errors | %d |\n", summary.ProjectsWithErrors)
+	}
+}
+
+// printMarkdownUpdate prints the updates of a single project
+func printMarkdownUpdate(out io.Writer, update *models.Update, defaultHostedURL string) {
+	if update == nil {
+		update = &models.Update{}
+	}
+
+	printed := false
+
+	// Print SDK updates
+	if env := update.EnvironmentUpdate; env != nil && (env.DartSDKVersion != nil || env.FlutterSDKVersion != nil) {
+		fmt.Fprintln(out, "### SDK Updates")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "| SDK | Latest |")
+		fmt.Fprintln(out, "|-----|--------|")
+		if env.DartSDKVersion != nil {
+			fmt.Fprintf(out, "| Dart | `%s` |\n", *env.DartSDKVersion)
+		}
+		if env.FlutterSDKVersion != nil {
+			fmt.Fprintf(out, "| Flutter | `%s` |\n", *env.FlutterSDKVersion)
+		}
+		fmt.Fprintln(out)
+		printed = true
+	}
+
+	// Print dependency updates, grouped by the pubspec.yaml section they belong to
+	for _, section := range models.DependencySections {
+		if deps := dependencyUpdatesIn(update.DependencyUpdates, section); len(deps) > 0 {
+			printMarkdownDependencyTable(out, sectionTitles[section], deps, defaultHostedURL)
+			printed = true
+		}
+	}
+
+	if len(update.TransitiveUpdates) > 0 {
+		printMarkdownDependencyTable(out, "Transitive Dependency Updates", update.TransitiveUpdates, defaultHostedURL)
+		printed = true
+	}
+
+	// Print updates that annotations in pubspec.yaml hold back
+	if len(update.HeldBack) > 0 {
+		fmt.Fprintln(out, "### Held Back")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "| Package | Current | Latest | Reason |")
+		fmt.Fprintln(out, "|---------|---------|--------|--------|")
+		for _, dep := range update.HeldBack {
+			fmt.Fprintf(out, "| %s | `%s` | `%s` | %s |\n",
+				markdownCell(dep.Name),
+				markdownCell(dep.CurrentVersion),
+				markdownCell(dep.LatestVersion),
+				markdownCell(dep.Reason))
+		}
+		fmt.Fprintln(out)
+		printed = true
+	}
+
+	// Print packages that could not be checked
+	if len(update.Failures) > 0 || len(update.Unknown) > 0 || update.SDKStatusUnknown {
+		fmt.Fprintln(out, "### Could not check")
+		fmt.Fprintln(out)
+		for _, failure := range update.Failures {
+			fmt.Fprintf(out, "- `%s`: %s\n", failure.Name, failureReason(failure))
+		}
+		if update.SDKStatusUnknown {
+			fmt.Fprintln(out, "- SDKs: not cached")
+		}
+		for _, packageName := range update.Unknown {
+			fmt.Fprintf(out, "- `%s`: not cached\n", packageName)
+		}
+		fmt.Fprintln(out)
+		printed = true
+	}
+
+	if !printed {
+		fmt.Fprintln(out, "Everything is up to date!")
+	}
+}
+
+// printMarkdownDependencyTable prints dependency updates as a Markdown table.
+// Packages from pub.dev link to their page and changelog.
+func printMarkdownDependencyTable(out io.Writer, title string, deps []models.DependencyUpdate, defaultHostedURL string) {
+	fmt.Fprintf(out, "### %s\n", title)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "| Package | Current | Latest | Update | Changelog |")
+	fmt.Fprintln(out, "|---------|---------|--------|--------|-----------|")
+
+	for _, dep := range deps {
+		name := markdownCell(dep.Name)
+		changelog := "-"
+		if pageURL := pubDevPageURL(dep, defaultHostedURL); pageURL != "" {
+			name = fmt.Sprintf("[%s](%s)", name, pageURL)
+			changelog = fmt.Sprintf("[changelog](%s/changelog)", pageURL)
+		}
+
+		fmt.Fprintf(out, "| %s | `%s` | `%s` | %s | %s |\n",
+			name,
+			markdownCell(dep.CurrentVersion),
+			markdownCell(dep.LatestVersion),
+			dep.Kind(),
+			changelog)
+	}
+
+	fmt.Fprintln(out)
+}
+
+// pubDevPageURL returns the pub.dev page of a package, or an empty string if
+// it doesn't come from pub.dev. Packages without a hosted URL come from the
+// default package repository.
+func pubDevPageURL(dep models.DependencyUpdate, defaultHostedURL string) string {
+	if dep.Source != "" && dep.Source != models.HostedSource {
+		return ""
+	}
+
+	hostedURL := dep.HostedURL
+	if hostedURL == "" {
+		hostedURL = defaultHostedURL
+	}
+	if hostedURL != "" && strings.TrimRight(hostedURL, "/") != DEFAULT_HOSTED_URL {
+		return ""
+	}
+
+	return fmt.Sprintf(PUB_DEV_PACKAGE_PAGE_URL, url.PathEscape(dep.Name))
+}
+
+// markdownCell escapes the characters that would break a table cell
+func markdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestMarkdownDisplayService_PrintUpdate(t *testing.T) {
+	printMarkdown := func(update *models.Update) string {
+		var output bytes.Buffer
+		displayService := &MarkdownDisplayService{Output: &output}
+		displayService.PrintUpdate(update)
+		return output.String()
+	}
+
+	t.Run("No updates", func(t *testing.T) {
+		assert.Equal(t, "Everything is up to date!\n", printMarkdown(nil))
+		assert.Equal(t, "Everything is up to date!\n", printMarkdown(&models.Update{}))
+	})
+
+	t.Run("Updates", func(t *testing.T) {
+		dartVersion := "3.5.0"
+		output := printMarkdown(&models.Update{
+			EnvironmentUpdate: &models.EnvironmentUpdate{DartSDKVersion: &dartVersion},
+			DependencyUpdates: []models.DependencyUpdate{
+				{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "2.0.0", Section: models.DependenciesSection},
+				{Name: "internal_pkg", CurrentVersion: "1.0.0", LatestVersion: "1.0.1", Section: models.DependenciesSection, HostedURL: "https://pub.example.com"},
+				{Name: "lints", CurrentVersion: "4.0.0", LatestVersion: "4.1.0", Section: models.DevDependenciesSection},
+			},
+			Failures: []models.PackageFailure{
+				{Name: "private", Cause: errors.New("connection refused")},
+			},
+		})
+
+		expected := "### SDK Updates\n" +
+			"\n" +
+			"| SDK | Latest |\n" +
+			"|-----|--------|\n" +
+			"| Dart | `3.5.0` |\n" +
+			"\n" +
+			"### Dependency Updates\n" +
+			"\n" +
+			"| Package | Current | Latest | Update | Changelog |\n" +
+			"|---------|---------|--------|--------|-----------|\n" +
+			"| [http](https://pub.dev/packages/http) | `1.1.0` | `2.0.0` | major | [changelog](https://pub.dev/packages/http/changelog) |\n" +
+			"| internal_pkg | `1.0.0` | `1.0.1` | patch | - |\n" +
+			"\n" +
+			"### Dev Dependency Updates\n" +
+			"\n" +
+			"| Package | Current | Latest | Update | Changelog |\n" +
+			"|---------|---------|--------|--------|-----------|\n" +
+			"| [lints](https://pub.dev/packages/lints) | `4.0.0` | `4.1.0` | minor | [changelog](https://pub.dev/packages/lints/changelog) |\n" +
+			"\n" +
+			"### Could not check\n" +
+			"\n" +
+			"- `private`: connection refused\n" +
+			"\n"
+		assert.Equal(t, expected, output)
+	})
+
+	t.Run("Git dependency", func(t *testing.T) {
+		output := printMarkdown(&models.Update{
+			DependencyUpdates: []models.DependencyUpdate{
+				{Name: "forked", CurrentVersion: "v1.0.0", LatestVersion: "v1.1.0", Source: models.GitSource},
+			},
+		})
+
+		assert.Contains(t, output, "| forked | `v1.0.0` | `v1.1.0` | minor | - |\n")
+	})
+
+	t.Run("Default repository other than pub.dev", func(t *testing.T) {
+		var output bytes.Buffer
+		displayService := &MarkdownDisplayService{Output: &output, DefaultHostedURL: "https://pub.example.com"}
+		displayService.PrintUpdate(&models.Update{
+			DependencyUpdates: []models.DependencyUpdate{
+				{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "1.2.0"},
+				{Name: "lints", CurrentVersion: "4.0.0", LatestVersion: "4.1.0", HostedURL: "https://pub.dev/"},
+			},
+		})
+
+		assert.Contains(t, output.String(), "| http | `1.1.0` | `1.2.0` | minor | - |\n")
+		assert.Contains(t, output.String(), "| [lints](https://pub.dev/packages/lints) | `4.0.0` | `4.1.0` | minor | [changelog](https://pub.dev/packages/lints/changelog) |\n")
+	})
+
+	t.Run("Held back", func(t *testing.T) {
+		output := printMarkdown(&models.Update{
+			HeldBack: []models.HeldBackUpdate{
+				{
+					DependencyUpdate: models.DependencyUpdate{Name: "intl", CurrentVersion: "0.18.0", LatestVersion: "0.20.2"},
+					Reason:           "max 0.19",
+				},
+			},
+		})
+
+		assert.Equal(t, "### Held Back\n"+
+			"\n"+
+			"| Package | Current | Latest | Reason |\n"+
+			"|---------|---------|--------|--------|\n"+
+			"| intl | `0.18.0` | `0.20.2` | max 0.19 |\n"+
+			"\n", output)
+	})
+}
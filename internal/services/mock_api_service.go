@@ -1,21 +1,28 @@
 package services
 
 import (
+	"context"
 	"github.com/sunderee/puby/internal/models"
 )
 
 // MockAPIService is a mock implementation of APIServiceInterface
 type MockAPIService struct {
-	GetSDKReleaseFunc func() (*models.SDKReleaseWrapper, error)
-	GetPackageFunc    func(packageName string) (*models.PackageWrapper, error)
+	GetSDKReleaseFunc    func() (*models.SDKReleaseWrapper, error)
+	GetPackageFunc       func(packageName string) (*models.PackageWrapper, error)
+	GetHostedPackageFunc func(hostedURL, packageName string) (*models.PackageWrapper, error)
 }
 
 // GetSDKRelease implements the APIServiceInterface
-func (m *MockAPIService) GetSDKRelease() (*models.SDKReleaseWrapper, error) {
+func (m *MockAPIService) GetSDKRelease(ctx context.Context) (*models.SDKReleaseWrapper, error) {
 	return m.GetSDKReleaseFunc()
 }
 
 // GetPackage implements the APIServiceInterface
-func (m *MockAPIService) GetPackage(packageName string) (*models.PackageWrapper, error) {
+func (m *MockAPIService) GetPackage(ctx context.Context, packageName string) (*models.PackageWrapper, error) {
 	return m.GetPackageFunc(packageName)
 }
+
+// GetHostedPackage implements the APIServiceInterface
+func (m *MockAPIService) GetHostedPackage(ctx context.Context, hostedURL, packageName string) (*models.PackageWrapper, error) {
+	return m.GetHostedPackageFunc(hostedURL, packageName)
+}
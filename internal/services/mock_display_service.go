@@ -6,7 +6,8 @@ import (
 
 // MockDisplayService is a mock implementation of DisplayServiceInterface
 type MockDisplayService struct {
-	PrintUpdateFunc func(update *models.Update)
+	PrintUpdateFunc          func(update *models.Update)
+	PrintWorkspaceUpdateFunc func(workspaceUpdate *models.WorkspaceUpdate)
 }
 
 // PrintUpdate implements the DisplayServiceInterface
@@ -15,3 +16,10 @@ func (m *MockDisplayService) PrintUpdate(update *models.Update) {
 		m.PrintUpdateFunc(update)
 	}
 }
+
+// PrintWorkspaceUpdate implements the DisplayServiceInterface
+func (m *MockDisplayService) PrintWorkspaceUpdate(workspaceUpdate *models.WorkspaceUpdate) {
+	if m.PrintWorkspaceUpdateFunc != nil {
+		m.PrintWorkspaceUpdateFunc(workspaceUpdate)
+	}
+}
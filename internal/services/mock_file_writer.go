@@ -6,7 +6,11 @@ import (
 
 // MockFileWriter is a mock implementation of the FileWriterInterface
 type MockFileWriter struct {
-	WriteUpdatesFunc func(update *models.Update) error
+	WriteUpdatesFunc   func(update *models.Update) error
+	PreviewUpdatesFunc func(update *models.Update) ([]byte, []byte, error)
+	RestoreBackupFunc  func() error
+	DiscardBackupFunc  func() error
+	WriteContentFunc   func(content []byte) error
 }
 
 // WriteUpdates implements the FileWriterInterface
@@ -16,3 +20,35 @@ func (m *MockFileWriter) WriteUpdates(update *models.Update) error {
 	}
 	return nil
 }
+
+// PreviewUpdates implements the FileWriterInterface
+func (m *MockFileWriter) PreviewUpdates(update *models.Update) ([]byte, []byte, error) {
+	if m.PreviewUpdatesFunc != nil {
+		return m.PreviewUpdatesFunc(update)
+	}
+	return nil, nil, nil
+}
+
+// RestoreBackup implements the FileWriterInterface
+func (m *MockFileWriter) RestoreBackup() error {
+	if m.RestoreBackupFunc != nil {
+		return m.RestoreBackupFunc()
+	}
+	return nil
+}
+
+// DiscardBackup implements the FileWriterInterface
+func (m *MockFileWriter) DiscardBackup() error {
+	if m.DiscardBackupFunc != nil {
+		return m.DiscardBackupFunc()
+	}
+	return nil
+}
+
+// WriteContent implements the FileWriterInterface
+func (m *MockFileWriter) WriteContent(content []byte) error {
+	if m.WriteContentFunc != nil {
+		return m.WriteContentFunc(content)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+// MockGitService is a mock implementation of GitServiceInterface
+type MockGitService struct {
+	ListRemoteRefsFunc func(ctx context.Context, repositoryURL string) (*models.GitRefs, error)
+}
+
+// ListRemoteRefs implements the GitServiceInterface
+func (m *MockGitService) ListRemoteRefs(ctx context.Context, repositoryURL string) (*models.GitRefs, error) {
+	return m.ListRemoteRefsFunc(ctx, repositoryURL)
+}
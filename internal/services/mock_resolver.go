@@ -0,0 +1,14 @@
+package services
+
+// MockResolver is a mock implementation of ResolverInterface
+type MockResolver struct {
+	ResolveFunc func() error
+}
+
+// Resolve implements the ResolverInterface
+func (m *MockResolver) Resolve() error {
+	if m.ResolveFunc != nil {
+		return m.ResolveFunc()
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+const DEFAULT_CONCURRENCY = 8
+
+// fetchPackages looks up the given packages, each in the repository it's
+// hosted on, using a bounded pool of workers. Results and errors are
+// index-aligned with the dependencies regardless of the order in which lookups
+// finish, so a failed lookup leaves a nil result and a non-nil error at its
+// index without affecting the others. Once the context is cancelled, running
+// lookups are aborted and the remaining ones are skipped, all failing with the
+// context error.
+func (s *UpdateService) fetchPackages(ctx context.Context, dependencies []models.Dependency) ([]*models.PackageWrapper, []error) {
+	results := make([]*models.PackageWrapper, len(dependencies))
+	errs := s.lookupConcurrently(ctx, len(dependencies), func(i int) error {
+		var err error
+		if dependencies[i].HostedURL == "" {
+			results[i], err = s.APIService.GetPackage(ctx, dependencies[i].Name)
+		} else {
+			results[i], err = s.APIService.GetHostedPackage(ctx, dependencies[i].HostedURL, dependencies[i].Name)
+		}
+		return err
+	})
+
+	return results, errs
+}
+
+// lookupConcurrently calls lookup for every index below count using a bounded
+// pool of workers and returns the errors index-aligned with the lookups. Once
+// the context is cancelled, the remaining lookups are skipped and fail with the
+// context error.
+func (s *UpdateService) lookupConcurrently(ctx context.Context, count int, lookup func(i int) error) []error {
+	errs := make([]error, count)
+
+	workers := min(s.concurrency(), count)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+
+				errs[i] = lookup(i)
+			}
+		}()
+	}
+
+	for i := range count {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errs
+}
+
+// concurrency returns the configured number of lookup workers
+func (s *UpdateService) concurrency() int {
+	if s.Config != nil && s.Config.Concurrency != nil && *s.Config.Concurrency > 0 {
+		return *s.Config.Concurrency
+	}
+
+	return DEFAULT_CONCURRENCY
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/models"
+	"github.com/sunderee/puby/internal/parsers"
+)
+
+func TestUpdateService_FetchPackages(t *testing.T) {
+	t.Run("results keep input order", func(t *testing.T) {
+		packageNames := []string{"a", "b", "c", "d", "e", "f"}
+		service := &UpdateService{
+			Config: &config.CLIConfig{Concurrency: intPtr(3)},
+			APIService: &MockAPIService{
+				GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+					// Finish lookups in reverse order
+					time.Sleep(time.Duration('f'-packageName[0]) * time.Millisecond)
+					return &models.PackageWrapper{Name: packageName}, nil
+				},
+			},
+		}
+
+		results, errs := service.fetchPackages(context.Background(), hostedDependencies(packageNames...))
+
+		for i, packageName := range packageNames {
+			assert.NoError(t, errs[i])
+			assert.Equal(t, packageName, results[i].Name)
+		}
+	})
+
+	t.Run("number of workers is bounded", func(t *testing.T) {
+		var inFlight, maxInFlight atomic.Int32
+		service := &UpdateService{
+			Config: &config.CLIConfig{Concurrency: intPtr(2)},
+			APIService: &MockAPIService{
+				GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+					current := inFlight.Add(1)
+					defer inFlight.Add(-1)
+					for {
+						observed := maxInFlight.Load()
+						if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+							break
+						}
+					}
+
+					time.Sleep(5 * time.Millisecond)
+					return &models.PackageWrapper{Name: packageName}, nil
+				},
+			},
+		}
+
+		_, errs := service.fetchPackages(context.Background(), hostedDependencies("a", "b", "c", "d", "e", "f", "g"))
+
+		assert.Equal(t, make([]error, 7), errs)
+		assert.Equal(t, int32(2), maxInFlight.Load())
+	})
+
+	t.Run("errors are collected per package", func(t *testing.T) {
+		service := &UpdateService{
+			Config: &config.CLIConfig{},
+			APIService: &MockAPIService{
+				GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+					if packageName == "missing" {
+						return nil, errors.New("server returned status code 404")
+					}
+					return &models.PackageWrapper{Name: packageName}, nil
+				},
+			},
+		}
+
+		results, errs := service.fetchPackages(context.Background(), hostedDependencies("http", "missing", "path"))
+
+		assert.Equal(t, "http", results[0].Name)
+		assert.Nil(t, results[1])
+		assert.Equal(t, "path", results[2].Name)
+		assert.NoError(t, errs[0])
+		assert.EqualError(t, errs[1], "server returned status code 404")
+		assert.NoError(t, errs[2])
+	})
+
+	t.Run("cancelled context skips lookups", func(t *testing.T) {
+		var calls atomic.Int32
+		service := &UpdateService{
+			Config: &config.CLIConfig{Concurrency: intPtr(1)},
+			APIService: &MockAPIService{
+				GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+					calls.Add(1)
+					return &models.PackageWrapper{Name: packageName}, nil
+				},
+			},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, errs := service.fetchPackages(ctx, hostedDependencies("http", "path"))
+
+		assert.Equal(t, int32(0), calls.Load())
+		assert.Equal(t, []*models.PackageWrapper{nil, nil}, results)
+		for _, err := range errs {
+			assert.ErrorIs(t, err, context.Canceled)
+		}
+	})
+}
+
+func TestUpdateService_CheckForUpdates_Failures(t *testing.T) {
+	var calls atomic.Int32
+	service := NewUpdateService(
+		&parsers.MockPubspecParser{
+			ParseFunc: func() (*models.Pubspec, error) {
+				return &models.Pubspec{
+					Environment: &models.PubspecEnvironment{DartSDKVersion: stringPtr("^3.0.0")},
+					Dependencies: map[string]any{
+						"http":     "^1.0.0",
+						"missing":  "^1.0.0",
+						"offline":  "^1.0.0",
+						"provider": "^6.0.0",
+					},
+				}, nil
+			},
+		},
+		&MockAPIService{
+			GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+				return &models.SDKReleaseWrapper{}, nil
+			},
+			GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+				calls.Add(1)
+				switch packageName {
+				case "missing":
+					return nil, &HTTPStatusError{StatusCode: 404}
+				case "offline":
+					return nil, errors.New("dial tcp: connection refused")
+				}
+				return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: "9.0.0"}}, nil
+			},
+		},
+	)
+	service.Config = &config.CLIConfig{}
+
+	update, err := service.CheckForUpdates()
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), calls.Load())
+	assert.Equal(t, []models.DependencyUpdate{
+		{Name: "http", CurrentVersion: "1.0.0", LatestVersion: "9.0.0", Constraint: "^1.0.0", Section: models.DependenciesSection},
+		{Name: "provider", CurrentVersion: "6.0.0", LatestVersion: "9.0.0", Constraint: "^6.0.0", Section: models.DependenciesSection},
+	}, update.DependencyUpdates)
+	assert.Equal(t, []models.PackageFailure{
+		{Name: "missing", StatusCode: 404, Cause: &HTTPStatusError{StatusCode: 404}},
+		{Name: "offline", StatusCode: 0, Cause: errors.New("dial tcp: connection refused")},
+	}, update.Failures)
+}
+
+// Helper function to create a pointer to an int
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/models"
+	"github.com/sunderee/puby/internal/parsers"
+	"github.com/sunderee/puby/internal/semver"
+)
+
+// pathOverrides maps the packages that dependency_overrides replaces with a
+// local package to the path of that package. It is nil if path dependencies
+// are not followed.
+func (s *UpdateService) pathOverrides(pubspec *models.Pubspec) map[string]string {
+	if s.ProjectDir == "" {
+		return nil
+	}
+
+	overrides := make(map[string]string)
+	for dependencyName, dependencyValue := range pubspec.DependencyOverrides {
+		dependency := models.ParseDependency(dependencyName, models.DependencyOverridesSection, dependencyValue)
+		if dependency.Source == models.PathSource && dependency.Path != "" {
+			overrides[dependencyName] = dependency.Path
+		}
+	}
+
+	return overrides
+}
+
+// produceSliceOfPathDependencies returns the dependencies that are required at
+// a version of a local package, sorted by name: path dependencies that declare
+// a version constraint, and hosted dependencies that dependency_overrides
+// replaces with a local package, as they are published with their constraint
+// but developed against the local package. Both must pass the include and
+// exclude filters.
+func (s *UpdateService) produceSliceOfPathDependencies(pubspec *models.Pubspec) []models.Dependency {
+	overrides := s.pathOverrides(pubspec)
+
+	var pathDependencies []models.Dependency
+	for _, section := range models.DependencySections {
+		for dependencyName, dependencyValue := range pubspec.DependenciesIn(section) {
+			dependency := models.ParseDependency(dependencyName, section, dependencyValue)
+			switch {
+			case dependency.Source == models.PathSource && dependency.Path != "" && dependency.Constraint != "":
+			case dependency.Source == models.HostedSource && section != models.DependencyOverridesSection && overrides[dependencyName] != "":
+				dependency.Source = models.PathSource
+				dependency.Path = overrides[dependencyName]
+			default:
+				continue
+			}
+
+			if s.isPackageSelected(dependencyName) {
+				pathDependencies = append(pathDependencies, dependency)
+			}
+		}
+	}
+
+	sort.SliceStable(pathDependencies, func(i, j int) bool {
+		return pathDependencies[i].Name < pathDependencies[j].Name
+	})
+
+	return pathDependencies
+}
+
+// checkPathDependencies reads the version of the local package every path
+// dependency points to and reports the dependencies whose constraint is behind
+// it, as far as the configured update policy and the package's rule allow.
+// Local packages that can't be read, or have no version, are reported as
+// failures.
+func (s *UpdateService) checkPathDependencies(pathDependencies []models.Dependency) ([]models.DependencyUpdate, []models.PackageFailure) {
+	policy := config.UpdatePolicyMajor
+	if s.Config != nil && s.Config.UpdatePolicy != nil {
+		policy = *s.Config.UpdatePolicy
+	}
+
+	var dependencyUpdates []models.DependencyUpdate
+	var failures []models.PackageFailure
+	failed := make(map[string]bool)
+	for _, dependency := range pathDependencies {
+		localVersion, err := s.localPackageVersion(dependency)
+		if err != nil {
+			if !failed[dependency.Name] {
+				failed[dependency.Name] = true
+				failures = append(failures, newPackageFailure(dependency.Name, err))
+			}
+			continue
+		}
+
+		currentVersion, isOutdated := compareWithConstraint(dependency.Constraint, localVersion.String())
+		if !isOutdated {
+			continue
+		}
+		constraint, err := semver.ParseConstraint(dependency.Constraint)
+		if (err != nil && policy != config.UpdatePolicyMajor) || (err == nil && !isAllowedByPolicy(policy, constraint, localVersion)) {
+			continue
+		}
+		if !s.packageRule(dependency.Name).Allows(localVersion) {
+			continue
+		}
+
+		dependencyUpdates = append(dependencyUpdates, models.DependencyUpdate{
+			Name:           dependency.Name,
+			CurrentVersion: currentVersion,
+			LatestVersion:  localVersion.String(),
+			Constraint:     dependency.Constraint,
+			Section:        dependency.Section,
+			Source:         models.PathSource,
+		})
+	}
+
+	return dependencyUpdates, failures
+}
+
+// localPackageVersion reads the version from the pubspec.yaml of the local
+// package a path dependency points to
+func (s *UpdateService) localPackageVersion(dependency models.Dependency) (semver.Version, error) {
+	packageDir := dependency.Path
+	if !filepath.IsAbs(packageDir) {
+		packageDir = filepath.Join(s.ProjectDir, filepath.FromSlash(packageDir))
+	}
+
+	pubspec, err := parsers.NewPubspecParser(filepath.Join(packageDir, PUBSPEC_FILE_NAME)).Parse()
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("failed to read local package at %s: %v", dependency.Path, err)
+	}
+	if pubspec.Name != "" && pubspec.Name != dependency.Name {
+		return semver.Version{}, fmt.Errorf("local package at %s is %s, not %s", dependency.Path, pubspec.Name, dependency.Name)
+	}
+	if pubspec.Version == "" {
+		return semver.Version{}, fmt.Errorf("local package at %s has no version", dependency.Path)
+	}
+
+	version, err := semver.Parse(pubspec.Version)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("local package at %s has an invalid version %q: %v", dependency.Path, pubspec.Version, err)
+	}
+
+	return version, nil
+}
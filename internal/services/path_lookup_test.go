@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/models"
+	"github.com/sunderee/puby/internal/parsers"
+)
+
+func TestUpdateService_CheckForUpdates_PathDependencies(t *testing.T) {
+	rootDir := t.TempDir()
+	writeFiles(t, rootDir, map[string]string{
+		"packages/core/pubspec.yaml":   "name: core\nversion: 2.1.0\n",
+		"packages/utils/pubspec.yaml":  "name: utils\nversion: 1.0.0\n",
+		"packages/models/pubspec.yaml": "name: models\nversion: 1.4.0\n",
+		"packages/legacy/pubspec.yaml": "name: legacy\n",
+		"apps/mobile/pubspec.yaml": `name: mobile
+dependencies:
+  core:
+    path: ../../packages/core
+    version: ^2.0.0
+  utils:
+    path: ../../packages/utils
+    version: ^1.0.0
+  models: ^1.2.0
+  legacy:
+    path: ../../packages/legacy
+    version: ^1.0.0
+  scratch:
+    path: ../../packages/scratch
+dependency_overrides:
+  models:
+    path: ../../packages/models
+`,
+	})
+
+	pubspecPath := filepath.Join(rootDir, "apps", "mobile", "pubspec.yaml")
+	service := NewUpdateService(parsers.NewPubspecParser(pubspecPath), &MockAPIService{
+		GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+			return nil, ErrNotCached
+		},
+		GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+			if packageName != "models" {
+				return nil, errors.New("unexpected lookup of " + packageName)
+			}
+			return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: "1.3.0"}}, nil
+		},
+	})
+	service.Config = &config.CLIConfig{}
+	service.ProjectDir = filepath.Dir(pubspecPath)
+
+	update, err := service.CheckForUpdates()
+	require.NoError(t, err)
+
+	assert.Equal(t, []models.DependencyUpdate{
+		{Name: "models", CurrentVersion: "1.2.0", LatestVersion: "1.3.0", Constraint: "^1.2.0", Section: models.DependenciesSection},
+		{Name: "core", CurrentVersion: "2.0.0", LatestVersion: "2.1.0", Constraint: "^2.0.0", Section: models.DependenciesSection, Source: models.PathSource},
+		{Name: "models", CurrentVersion: "1.2.0", LatestVersion: "1.4.0", Constraint: "^1.2.0", Section: models.DependenciesSection, Source: models.PathSource},
+	}, update.DependencyUpdates)
+
+	require.Len(t, update.Failures, 1)
+	assert.Equal(t, "legacy", update.Failures[0].Name)
+	assert.ErrorContains(t, update.Failures[0].Cause, "has no version")
+
+	var checked []string
+	for _, dependency := range update.Checked {
+		checked = append(checked, dependency.Name)
+	}
+	assert.Equal(t, []string{"models", "core", "legacy", "utils"}, checked)
+}
+
+func TestUpdateService_CheckForUpdates_UnpublishedOverride(t *testing.T) {
+	rootDir := t.TempDir()
+	writeFiles(t, rootDir, map[string]string{
+		"packages/models/pubspec.yaml": "name: models\nversion: 1.4.0\n",
+		"apps/mobile/pubspec.yaml": `name: mobile
+dependencies:
+  models: ^1.2.0
+  http: ^1.0.0
+dependency_overrides:
+  models:
+    path: ../../packages/models
+`,
+	})
+
+	pubspecPath := filepath.Join(rootDir, "apps", "mobile", "pubspec.yaml")
+	service := NewUpdateService(parsers.NewPubspecParser(pubspecPath), &MockAPIService{
+		GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+			return nil, ErrNotCached
+		},
+		GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+			return nil, &HTTPStatusError{StatusCode: http.StatusNotFound}
+		},
+	})
+	service.Config = &config.CLIConfig{}
+	service.ProjectDir = filepath.Dir(pubspecPath)
+
+	update, err := service.CheckForUpdates()
+	require.NoError(t, err)
+
+	// Only the package that isn't overridden fails
+	assert.Equal(t, []models.DependencyUpdate{
+		{Name: "models", CurrentVersion: "1.2.0", LatestVersion: "1.4.0", Constraint: "^1.2.0", Section: models.DependenciesSection, Source: models.PathSource},
+	}, update.DependencyUpdates)
+	require.Len(t, update.Failures, 1)
+	assert.Equal(t, "http", update.Failures[0].Name)
+	assert.Equal(t, http.StatusNotFound, update.Failures[0].StatusCode)
+}
+
+func TestUpdateService_CheckPathDependencies(t *testing.T) {
+	projectDir := t.TempDir()
+	writeFiles(t, projectDir, map[string]string{
+		"core/pubspec.yaml":  "name: core\nversion: 3.2.1\n",
+		"other/pubspec.yaml": "name: other\nversion: 1.0.0\n",
+	})
+
+	tests := []struct {
+		name       string
+		dependency models.Dependency
+		policy     *config.UpdatePolicy
+		expected   string
+		failure    string
+	}{
+		{name: "behind", dependency: models.Dependency{Name: "core", Path: "core", Constraint: "^3.0.0"}, expected: "3.2.1"},
+		{name: "up to date", dependency: models.Dependency{Name: "core", Path: "core", Constraint: "^3.2.1"}},
+		{name: "range allowing the local version", dependency: models.Dependency{Name: "core", Path: "core", Constraint: "<4.0.0"}},
+		{name: "absolute path", dependency: models.Dependency{Name: "core", Path: filepath.Join(projectDir, "core"), Constraint: "^2.0.0"}, expected: "3.2.1"},
+		{name: "minor policy", dependency: models.Dependency{Name: "core", Path: "core", Constraint: "^2.0.0"}, policy: updatePolicyPtr(config.UpdatePolicyMinor)},
+		{name: "patch policy", dependency: models.Dependency{Name: "core", Path: "core", Constraint: "^3.2.0"}, policy: updatePolicyPtr(config.UpdatePolicyPatch), expected: "3.2.1"},
+		{name: "missing package", dependency: models.Dependency{Name: "core", Path: "missing", Constraint: "^3.0.0"}, failure: "failed to read local package"},
+		{name: "different package", dependency: models.Dependency{Name: "core", Path: "other", Constraint: "^3.0.0"}, failure: "is other, not core"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &UpdateService{Config: &config.CLIConfig{UpdatePolicy: tt.policy}, ProjectDir: projectDir}
+			updates, failures := service.checkPathDependencies([]models.Dependency{tt.dependency})
+
+			if tt.failure != "" {
+				assert.Empty(t, updates)
+				require.Len(t, failures, 1)
+				assert.ErrorContains(t, failures[0].Cause, tt.failure)
+				return
+			}
+
+			assert.Empty(t, failures)
+			if tt.expected == "" {
+				assert.Empty(t, updates)
+				return
+			}
+			require.Len(t, updates, 1)
+			assert.Equal(t, tt.expected, updates[0].LatestVersion)
+		})
+	}
+}
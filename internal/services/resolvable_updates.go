@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+// ApplyResolvableUpdates writes as many of the updates as the project resolves
+// with. The SDK updates are tried on their own first, and are part of every
+// later attempt if they resolve; otherwise they are reported as a conflict and
+// left out. All dependency updates are then tried together. If they don't
+// resolve, they are split in halves and each half is tried on top of what has
+// been kept so far, down to single updates, which are reported as conflicts
+// along with the resolver output.
+//
+// pubspec.yaml is left with the kept SDK and dependency updates. If the
+// resolver fails for another reason, the file is put back the way it was and
+// the error is returned.
+func ApplyResolvableUpdates(fileWriter FileWriterInterface, resolver ResolverInterface, update *models.Update) (*models.ResolutionResult, error) {
+	original, _, err := fileWriter.PreviewUpdates(update)
+	if err != nil {
+		return nil, err
+	}
+
+	bisection := &updateBisection{
+		fileWriter:        fileWriter,
+		resolver:          resolver,
+		original:          original,
+		environmentUpdate: update.EnvironmentUpdate,
+		result:            &models.ResolutionResult{},
+	}
+
+	err = bisection.processEnvironment()
+	if err == nil {
+		err = bisection.process(update.DependencyUpdates)
+	}
+	if err != nil {
+		return nil, revertUpdates(fileWriter, original, err)
+	}
+
+	// Leave the file with the updates that were kept, which is the last set
+	// that resolved
+	if err := bisection.write(bisection.result.Applied); err != nil {
+		return nil, err
+	}
+
+	return bisection.result, nil
+}
+
+// updateBisection keeps track of the updates kept while bisecting
+type updateBisection struct {
+	fileWriter        FileWriterInterface
+	resolver          ResolverInterface
+	original          []byte
+	environmentUpdate *models.EnvironmentUpdate
+	result            *models.ResolutionResult
+}
+
+// processEnvironment tries the SDK updates on their own, leaving them out of
+// the following attempts if the project doesn't resolve with them
+func (b *updateBisection) processEnvironment() error {
+	env := b.environmentUpdate
+	if env == nil || (env.DartSDKVersion == nil && env.FlutterSDKVersion == nil) {
+		return nil
+	}
+
+	if err := b.write(nil); err != nil {
+		return err
+	}
+
+	err := b.resolver.Resolve()
+	var resolutionError *ResolutionError
+	switch {
+	case err == nil:
+		b.result.EnvironmentUpdate = env
+		return nil
+	case !errors.As(err, &resolutionError):
+		return err
+	}
+
+	b.result.EnvironmentConflict = &models.EnvironmentConflict{
+		Update: *env,
+		Reason: resolutionError.Output,
+	}
+	b.environmentUpdate = nil
+	return nil
+}
+
+// process tries the candidates on top of the updates kept so far, splitting
+// them in halves while they don't resolve
+func (b *updateBisection) process(candidates []models.DependencyUpdate) error {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	attempt := append(append([]models.DependencyUpdate{}, b.result.Applied...), candidates...)
+	if err := b.write(attempt); err != nil {
+		return err
+	}
+
+	err := b.resolver.Resolve()
+	var resolutionError *ResolutionError
+	switch {
+	case err == nil:
+		b.result.Applied = attempt
+		return nil
+	case !errors.As(err, &resolutionError):
+		return err
+	case len(candidates) == 1:
+		b.result.Conflicts = append(b.result.Conflicts, models.UpdateConflict{
+			Update: candidates[0],
+			Reason: resolutionError.Output,
+		})
+		return nil
+	}
+
+	middle := len(candidates) / 2
+	if err := b.process(candidates[:middle]); err != nil {
+		return err
+	}
+	return b.process(candidates[middle:])
+}
+
+// write puts the SDK updates and the given dependency updates into the
+// original pubspec.yaml
+func (b *updateBisection) write(dependencyUpdates []models.DependencyUpdate) error {
+	if err := b.fileWriter.WriteContent(b.original); err != nil {
+		return err
+	}
+	if b.environmentUpdate == nil && len(dependencyUpdates) == 0 {
+		return b.fileWriter.DiscardBackup()
+	}
+
+	return b.fileWriter.WriteUpdates(&models.Update{
+		EnvironmentUpdate: b.environmentUpdate,
+		DependencyUpdates: dependencyUpdates,
+	})
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestApplyResolvableUpdates(t *testing.T) {
+	initialContent := "name: test_app\nenvironment:\n  sdk: ^3.0.0\ndependencies:\n  a: ^1.0.0\n  b: ^1.0.0\n  c: ^1.0.0\n  d: ^1.0.0\n"
+	update := &models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "a", CurrentVersion: "1.0.0", LatestVersion: "2.0.0", Section: models.DependenciesSection},
+			{Name: "b", CurrentVersion: "1.0.0", LatestVersion: "2.0.0", Section: models.DependenciesSection},
+			{Name: "c", CurrentVersion: "1.0.0", LatestVersion: "2.0.0", Section: models.DependenciesSection},
+			{Name: "d", CurrentVersion: "1.0.0", LatestVersion: "2.0.0", Section: models.DependenciesSection},
+		},
+	}
+
+	// setup writes the pubspec and returns a writer for it along with a
+	// resolver that fails whenever the file contains one of the given lines
+	setup := func(t *testing.T, conflictingLines ...string) (string, FileWriterInterface, *MockResolver, *int) {
+		pubspecPath := filepath.Join(t.TempDir(), "pubspec.yaml")
+		if err := os.WriteFile(pubspecPath, []byte(initialContent), 0644); err != nil {
+			t.Fatalf("Failed to write test file: %v", err)
+		}
+
+		resolves := 0
+		resolver := &MockResolver{
+			ResolveFunc: func() error {
+				resolves++
+				content, _ := os.ReadFile(pubspecPath)
+				for _, line := range conflictingLines {
+					if strings.Contains(string(content), line) {
+						return &ResolutionError{Command: "dart pub get", Output: "conflict on " + line}
+					}
+				}
+				return nil
+			},
+		}
+
+		return pubspecPath, &FileWriterService{PubspecFilePath: pubspecPath, Backup: true}, resolver, &resolves
+	}
+
+	t.Run("all updates resolve", func(t *testing.T) {
+		pubspecPath, fileWriter, resolver, resolves := setup(t)
+
+		result, err := ApplyResolvableUpdates(fileWriter, resolver, update)
+
+		assert.NoError(t, err)
+		assert.Equal(t, update.DependencyUpdates, result.Applied)
+		assert.Empty(t, result.Conflicts)
+		assert.Equal(t, 1, *resolves)
+
+		content, _ := os.ReadFile(pubspecPath)
+		assert.Equal(t, "name: test_app\nenvironment:\n  sdk: ^3.0.0\ndependencies:\n  a: ^2.0.0\n  b: ^2.0.0\n  c: ^2.0.0\n  d: ^2.0.0\n", string(content))
+
+		backup, _ := os.ReadFile(pubspecPath + BACKUP_FILE_SUFFIX)
+		assert.Equal(t, initialContent, string(backup))
+	})
+
+	t.Run("no update resolves", func(t *testing.T) {
+		pubspecPath, fileWriter, resolver, _ := setup(t, "a: ^2.0.0", "b: ^2.0.0", "c: ^2.0.0", "d: ^2.0.0")
+
+		result, err := ApplyResolvableUpdates(fileWriter, resolver, update)
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Applied)
+		assert.Len(t, result.Conflicts, 4)
+
+		content, _ := os.ReadFile(pubspecPath)
+		assert.Equal(t, initialContent, string(content))
+
+		// The file is unchanged, so no backup is left behind
+		_, err = os.Stat(pubspecPath + BACKUP_FILE_SUFFIX)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("conflicting updates are left out", func(t *testing.T) {
+		pubspecPath, fileWriter, resolver, _ := setup(t, "b: ^2.0.0", "d: ^2.0.0")
+
+		result, err := ApplyResolvableUpdates(fileWriter, resolver, update)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.DependencyUpdate{update.DependencyUpdates[0], update.DependencyUpdates[2]}, result.Applied)
+		assert.Equal(t, []models.UpdateConflict{
+			{Update: update.DependencyUpdates[1], Reason: "conflict on b: ^2.0.0"},
+			{Update: update.DependencyUpdates[3], Reason: "conflict on d: ^2.0.0"},
+		}, result.Conflicts)
+
+		content, _ := os.ReadFile(pubspecPath)
+		assert.Equal(t, "name: test_app\nenvironment:\n  sdk: ^3.0.0\ndependencies:\n  a: ^2.0.0\n  b: ^1.0.0\n  c: ^2.0.0\n  d: ^1.0.0\n", string(content))
+	})
+
+	dartSDKVersion := "3.5.0"
+	environmentUpdate := &models.EnvironmentUpdate{DartSDKVersion: &dartSDKVersion}
+
+	t.Run("SDK update on its own is resolved", func(t *testing.T) {
+		pubspecPath, fileWriter, resolver, resolves := setup(t)
+
+		result, err := ApplyResolvableUpdates(fileWriter, resolver, &models.Update{EnvironmentUpdate: environmentUpdate})
+
+		assert.NoError(t, err)
+		assert.Equal(t, environmentUpdate, result.EnvironmentUpdate)
+		assert.Nil(t, result.EnvironmentConflict)
+		assert.Equal(t, 1, *resolves)
+
+		content, _ := os.ReadFile(pubspecPath)
+		assert.Contains(t, string(content), "sdk: ^3.5.0")
+	})
+
+	t.Run("conflicting SDK update is left out", func(t *testing.T) {
+		pubspecPath, fileWriter, resolver, _ := setup(t, "sdk: ^3.5.0", "d: ^2.0.0")
+
+		result, err := ApplyResolvableUpdates(fileWriter, resolver, &models.Update{
+			EnvironmentUpdate: environmentUpdate,
+			DependencyUpdates: update.DependencyUpdates,
+		})
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.EnvironmentUpdate)
+		assert.Equal(t, &models.EnvironmentConflict{Update: *environmentUpdate, Reason: "conflict on sdk: ^3.5.0"}, result.EnvironmentConflict)
+		assert.Equal(t, update.DependencyUpdates[:3], result.Applied)
+		assert.Equal(t, []models.UpdateConflict{
+			{Update: update.DependencyUpdates[3], Reason: "conflict on d: ^2.0.0"},
+		}, result.Conflicts)
+
+		content, _ := os.ReadFile(pubspecPath)
+		assert.Equal(t, "name: test_app\nenvironment:\n  sdk: ^3.0.0\ndependencies:\n  a: ^2.0.0\n  b: ^2.0.0\n  c: ^2.0.0\n  d: ^1.0.0\n", string(content))
+	})
+
+	t.Run("resolver errors revert the file", func(t *testing.T) {
+		pubspecPath, fileWriter, _, _ := setup(t)
+		resolver := &MockResolver{
+			ResolveFunc: func() error {
+				return errors.New("dart: command not found")
+			},
+		}
+
+		result, err := ApplyResolvableUpdates(fileWriter, resolver, update)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+
+		content, _ := os.ReadFile(pubspecPath)
+		assert.Equal(t, initialContent, string(content))
+
+		_, err = os.Stat(pubspecPath + BACKUP_FILE_SUFFIX)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
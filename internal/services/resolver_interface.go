@@ -0,0 +1,7 @@
+package services
+
+// ResolverInterface defines the interface for resolving the dependencies of a
+// project
+type ResolverInterface interface {
+	Resolve() error
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+const (
+	DART_EXECUTABLE    = "dart"
+	FLUTTER_EXECUTABLE = "flutter"
+)
+
+// ResolutionError is returned when pub get runs but can't resolve the
+// dependencies. It carries the solver output explaining why.
+type ResolutionError struct {
+	Command string
+	Output  string
+}
+
+func (e *ResolutionError) Error() string {
+	return fmt.Sprintf("%s failed to resolve dependencies", e.Command)
+}
+
+// PubResolver resolves the dependencies of a project by running pub get in its
+// directory, through the flutter tool for Flutter projects and the dart tool
+// otherwise
+type PubResolver struct {
+	ProjectDir string
+	Executable string
+}
+
+// NewPubResolver creates a new instance of PubResolver for the project in the
+// given directory, picking the tool based on whether the pubspec depends on
+// the Flutter SDK
+func NewPubResolver(projectDir string, pubspec *models.Pubspec) *PubResolver {
+	executable := DART_EXECUTABLE
+	if pubspec.UsesFlutter() {
+		executable = FLUTTER_EXECUTABLE
+	}
+
+	return &PubResolver{
+		ProjectDir: projectDir,
+		Executable: executable,
+	}
+}
+
+// Resolve implements the ResolverInterface
+func (r *PubResolver) Resolve() error {
+	cmd := exec.Command(r.Executable, "pub", "get")
+	cmd.Dir = r.ProjectDir
+
+	output, err := cmd.CombinedOutput()
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return &ResolutionError{
+			Command: r.Executable + " pub get",
+			Output:  strings.TrimSpace(string(output)),
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s pub get: %v", r.Executable, err)
+	}
+
+	return nil
+}
+
+// WriteAndVerifyUpdates writes the updates and resolves the project with them.
+// If resolution fails, pubspec.yaml is put back the way it was, the backup made
+// for the rejected write is removed and the resolver's error is returned.
+func WriteAndVerifyUpdates(fileWriter FileWriterInterface, resolver ResolverInterface, update *models.Update) error {
+	original, _, err := fileWriter.PreviewUpdates(update)
+	if err != nil {
+		return err
+	}
+
+	if err := fileWriter.WriteUpdates(update); err != nil {
+		return err
+	}
+
+	if err := resolver.Resolve(); err != nil {
+		return revertUpdates(fileWriter, original, err)
+	}
+
+	return nil
+}
+
+// revertUpdates puts the original content of pubspec.yaml back after err and
+// removes the backup of the reverted write, returning err
+func revertUpdates(fileWriter FileWriterInterface, original []byte, err error) error {
+	if revertErr := fileWriter.WriteContent(original); revertErr != nil {
+		return fmt.Errorf("%v, and reverting pubspec.yaml failed: %v", err, revertErr)
+	}
+	if discardErr := fileWriter.DiscardBackup(); discardErr != nil {
+		return fmt.Errorf("%v, and %v", err, discardErr)
+	}
+
+	return err
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sunderee/puby/internal/models"
+)
+
+// installFakeExecutable puts a shell script with the given name and body first
+// on PATH for the rest of the test
+func installFakeExecutable(t *testing.T, name, body string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake executables are shell scripts")
+	}
+
+	binDir := t.TempDir()
+	script := "#!/bin/sh\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake executable: %v", err)
+	}
+
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestNewPubResolver(t *testing.T) {
+	dartPubspec := &models.Pubspec{Dependencies: map[string]any{"http": "^1.0.0"}}
+	flutterPubspec := &models.Pubspec{Dependencies: map[string]any{"flutter": map[string]any{"sdk": "flutter"}}}
+
+	assert.Equal(t, DART_EXECUTABLE, NewPubResolver(".", dartPubspec).Executable)
+	assert.Equal(t, FLUTTER_EXECUTABLE, NewPubResolver(".", flutterPubspec).Executable)
+}
+
+func TestPubResolver_Resolve(t *testing.T) {
+	t.Run("resolution succeeds", func(t *testing.T) {
+		projectDir := t.TempDir()
+		installFakeExecutable(t, DART_EXECUTABLE, `[ "$1 $2" = "pub get" ] && touch ran-in-project-dir`)
+
+		resolver := &PubResolver{ProjectDir: projectDir, Executable: DART_EXECUTABLE}
+		assert.NoError(t, resolver.Resolve())
+		assert.FileExists(t, filepath.Join(projectDir, "ran-in-project-dir"))
+	})
+
+	t.Run("resolution fails", func(t *testing.T) {
+		installFakeExecutable(t, FLUTTER_EXECUTABLE, `echo "Because app depends on http ^9.0.0 which doesn't match any versions, version solving failed."; exit 1`)
+
+		resolver := &PubResolver{ProjectDir: t.TempDir(), Executable: FLUTTER_EXECUTABLE}
+		err := resolver.Resolve()
+
+		var resolutionError *ResolutionError
+		assert.ErrorAs(t, err, &resolutionError)
+		assert.Equal(t, "flutter pub get", resolutionError.Command)
+		assert.Contains(t, resolutionError.Output, "version solving failed")
+	})
+}
+
+func TestWriteAndVerifyUpdates(t *testing.T) {
+	initialContent := "name: test_app\ndependencies:\n  http: ^0.13.3\n"
+	update := &models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "http", CurrentVersion: "0.13.3", LatestVersion: "1.2.0", Section: models.DependenciesSection},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		resolveErr      error
+		expectedContent string
+		expectedBackup  bool
+	}{
+		{name: "resolved updates are kept", expectedContent: "name: test_app\ndependencies:\n  http: ^1.2.0\n", expectedBackup: true},
+		{name: "unresolved updates are reverted", resolveErr: &ResolutionError{Command: "dart pub get"}, expectedContent: initialContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubspecPath := filepath.Join(t.TempDir(), "pubspec.yaml")
+			if err := os.WriteFile(pubspecPath, []byte(initialContent), 0644); err != nil {
+				t.Fatalf("Failed to write test file: %v", err)
+			}
+
+			var resolvedContent string
+			resolver := &MockResolver{
+				ResolveFunc: func() error {
+					content, _ := os.ReadFile(pubspecPath)
+					resolvedContent = string(content)
+					return tt.resolveErr
+				},
+			}
+
+			fileWriter := &FileWriterService{PubspecFilePath: pubspecPath, Backup: true}
+			err := WriteAndVerifyUpdates(fileWriter, resolver, update)
+			if tt.resolveErr != nil {
+				assert.ErrorIs(t, err, tt.resolveErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// The resolver always sees the updated file
+			assert.Contains(t, resolvedContent, "http: ^1.2.0")
+
+			content, err := os.ReadFile(pubspecPath)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedContent, string(content))
+
+			// Only a write that is kept leaves a backup behind
+			_, err = os.Stat(pubspecPath + BACKUP_FILE_SUFFIX)
+			assert.Equal(t, tt.expectedBackup, err == nil)
+		})
+	}
+}
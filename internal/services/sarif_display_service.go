@@ -0,0 +1,301 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sunderee/puby/internal/models"
+	"github.com/sunderee/puby/internal/parsers"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SARIF_VERSION    = "2.1.0"
+	SARIF_SCHEMA_URI = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// Rule IDs of the SARIF results
+const (
+	sarifRuleSDKUpdate        = "sdk-update"
+	sarifRuleDependencyUpdate = "dependency-update"
+	sarifRuleCheckFailed      = "check-failed"
+)
+
+// SARIFDisplayService prints updates as a SARIF log, so code scanning tools
+// can show them on the lines of pubspec.yaml they apply to
+type SARIFDisplayService struct {
+	Output          io.Writer
+	PubspecFilePath string
+}
+
+// NewSARIFDisplayService creates a new instance of SARIFDisplayService that
+// prints to stdout
+func NewSARIFDisplayService(pubspecFilePath string) DisplayServiceInterface {
+	return &SARIFDisplayService{
+		Output:          os.Stdout,
+		PubspecFilePath: pubspecFilePath,
+	}
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// sarifArtifact is a YAML file results point into. The document is nil if
+// the file could not be read, in which case results point to the whole file.
+type sarifArtifact struct {
+	uri      string
+	document *parsers.PubspecDocument
+}
+
+// PrintUpdate implements the DisplayServiceInterface
+func (s *SARIFDisplayService) PrintUpdate(update *models.Update) {
+	lockfilePath := filepath.Join(filepath.Dir(s.PubspecFilePath), LOCKFILE_FILE_NAME)
+	s.encode(newSARIFResults(update, s.PubspecFilePath, lockfilePath))
+}
+
+// PrintWorkspaceUpdate implements the DisplayServiceInterface. Projects that
+// could not be checked are reported on their pubspec.yaml as a whole.
+func (s *SARIFDisplayService) PrintWorkspaceUpdate(workspaceUpdate *models.WorkspaceUpdate) {
+	results := []sarifResult{}
+	for _, project := range workspaceUpdate.Projects {
+		pubspecFilePath := filepath.Join(workspaceUpdate.RootDir, project.PubspecPath)
+		if project.Err != nil {
+			results = append(results, sarifResult{
+				RuleID:    sarifRuleCheckFailed,
+				Level:     "error",
+				Message:   sarifMessage{Text: fmt.Sprintf("%s could not be checked: %v", project.PubspecPath, project.Err)},
+				Locations: []sarifLocation{newSARIFArtifact(pubspecFilePath).location(nil)},
+			})
+			continue
+		}
+
+		lockfilePath := FindLockfile(filepath.Dir(pubspecFilePath), workspaceUpdate.RootDir)
+		results = append(results, newSARIFResults(project.Update, pubspecFilePath, lockfilePath)...)
+	}
+
+	s.encode(results)
+}
+
+// newSARIFResults creates the results for the updates of a single project
+func newSARIFResults(update *models.Update, pubspecFilePath, lockfilePath string) []sarifResult {
+	if update == nil {
+		update = &models.Update{}
+	}
+
+	pubspec := newSARIFArtifact(pubspecFilePath)
+	lockfile := newSARIFArtifact(lockfilePath)
+
+	results := []sarifResult{}
+
+	if env := update.EnvironmentUpdate; env != nil {
+		sdkUpdates := []struct {
+			key     string
+			name    string
+			version *string
+		}{
+			{key: "sdk", name: "Dart SDK", version: env.DartSDKVersion},
+			{key: "flutter", name: "Flutter SDK", version: env.FlutterSDKVersion},
+		}
+
+		for _, sdkUpdate := range sdkUpdates {
+			if sdkUpdate.version == nil {
+				continue
+			}
+
+			results = append(results, sarifResult{
+				RuleID:    sarifRuleSDKUpdate,
+				Level:     "note",
+				Message:   sarifMessage{Text: fmt.Sprintf("%s %s is available", sdkUpdate.name, *sdkUpdate.version)},
+				Locations: []sarifLocation{pubspec.location(pubspec.lookup("environment", sdkUpdate.key))},
+			})
+		}
+	}
+
+	for _, dep := range update.DependencyUpdates {
+		section := dep.Section
+		if section == "" {
+			section = models.DependenciesSection
+		}
+
+		var node *yaml.Node
+		if pubspec.document != nil {
+			node, _ = dependencyValueNode(pubspec.document, section, dep)
+		}
+		if node == nil {
+			node = pubspec.lookup(string(section), dep.Name)
+		}
+
+		results = append(results, newSARIFDependencyResult(dep, pubspec.location(node)))
+	}
+
+	for _, dep := range update.TransitiveUpdates {
+		location := lockfile.location(lockfile.lookup("packages", dep.Name, "version"))
+		results = append(results, newSARIFDependencyResult(dep, location))
+	}
+
+	for _, failure := range update.Failures {
+		var node *yaml.Node
+		for _, section := range models.DependencySections {
+			if node = pubspec.lookup(string(section), failure.Name); node != nil {
+				break
+			}
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    sarifRuleCheckFailed,
+			Level:     "warning",
+			Message:   sarifMessage{Text: fmt.Sprintf("%s could not be checked: %s", failure.Name, failureReason(failure))},
+			Locations: []sarifLocation{pubspec.location(node)},
+		})
+	}
+
+	return results
+}
+
+// encode prints the SARIF log with the given results
+func (s *SARIFDisplayService) encode(results []sarifResult) {
+	log := sarifLog{
+		Schema:  SARIF_SCHEMA_URI,
+		Version: SARIF_VERSION,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "puby",
+						InformationURI: "https://github.com/sunderee/puby",
+						Rules: []sarifRule{
+							{ID: sarifRuleSDKUpdate, ShortDescription: sarifMessage{Text: "A newer SDK version is available"}},
+							{ID: sarifRuleDependencyUpdate, ShortDescription: sarifMessage{Text: "A newer dependency version is available"}},
+							{ID: sarifRuleCheckFailed, ShortDescription: sarifMessage{Text: "The dependency or project could not be checked for updates"}},
+						},
+					},
+				},
+				ColumnKind: "unicodeCodePoints",
+				Results:    results,
+			},
+		},
+	}
+
+	encoder := json.NewEncoder(s.Output)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding SARIF output: %v\n", err)
+	}
+}
+
+// newSARIFDependencyResult creates the result for a dependency update. Major
+// updates are warnings, smaller ones notes.
+func newSARIFDependencyResult(dep models.DependencyUpdate, location sarifLocation) sarifResult {
+	kind := dep.Kind()
+	level := "note"
+	if kind == models.UpdateKindMajor {
+		level = "warning"
+	}
+
+	return sarifResult{
+		RuleID:    sarifRuleDependencyUpdate,
+		Level:     level,
+		Message:   sarifMessage{Text: fmt.Sprintf("%s can be updated from %s to %s (%s)", dep.Name, dep.CurrentVersion, dep.LatestVersion, kind)},
+		Locations: []sarifLocation{location},
+	}
+}
+
+// newSARIFArtifact reads a YAML file results point into. Its URI is relative
+// to the working directory if the file is inside it, as code scanning tools
+// expect paths relative to the repository.
+func newSARIFArtifact(path string) *sarifArtifact {
+	artifact := &sarifArtifact{uri: "file://" + filepath.ToSlash(path)}
+	if workingDir, err := os.Getwd(); err == nil {
+		if relativePath, err := filepath.Rel(workingDir, path); err == nil && !strings.HasPrefix(relativePath, "..") {
+			artifact.uri = filepath.ToSlash(relativePath)
+		}
+	}
+
+	if content, err := os.ReadFile(path); err == nil {
+		artifact.document, _ = parsers.ParsePubspecDocument(content)
+	}
+
+	return artifact
+}
+
+// lookup finds the node at the given path, or returns nil if the file could
+// not be read or the node does not exist
+func (a *sarifArtifact) lookup(path ...string) *yaml.Node {
+	if a.document == nil {
+		return nil
+	}
+
+	return a.document.Lookup(path...)
+}
+
+// location points to the node within the artifact, or to the whole artifact
+// if the node is nil
+func (a *sarifArtifact) location(node *yaml.Node) sarifLocation {
+	location := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: a.uri},
+		},
+	}
+	if node != nil {
+		location.PhysicalLocation.Region = &sarifRegion{StartLine: node.Line, StartColumn: node.Column}
+	}
+
+	return location
+}
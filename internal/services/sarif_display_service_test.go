@@ -0,0 +1,100 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestSARIFDisplayService_PrintUpdate(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	pubspecFilePath := filepath.Join(tempDir, "pubspec.yaml")
+	pubspec := `name: example
+environment:
+  sdk: ^3.0.0
+dependencies:
+  http: ^1.1.0
+  provider:
+    version: ^6.0.0
+dev_dependencies:
+  missing: ^1.0.0
+`
+	require.NoError(t, os.WriteFile(pubspecFilePath, []byte(pubspec), 0644))
+
+	dartVersion := "3.5.0"
+	update := &models.Update{
+		EnvironmentUpdate: &models.EnvironmentUpdate{DartSDKVersion: &dartVersion},
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "2.0.0", Section: models.DependenciesSection},
+			{Name: "provider", CurrentVersion: "6.0.0", LatestVersion: "6.1.0", Section: models.DependenciesSection},
+		},
+		Failures: []models.PackageFailure{
+			{Name: "missing", StatusCode: 404},
+		},
+	}
+
+	var output bytes.Buffer
+	displayService := &SARIFDisplayService{Output: &output, PubspecFilePath: pubspecFilePath}
+	displayService.PrintUpdate(update)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(output.Bytes(), &log))
+
+	assert.Equal(t, SARIF_VERSION, log.Version)
+	require.Len(t, log.Runs, 1)
+
+	type resultSummary struct {
+		RuleID string
+		Level  string
+		URI    string
+		Line   int
+		Column int
+	}
+	var summaries []resultSummary
+	for _, result := range log.Runs[0].Results {
+		require.Len(t, result.Locations, 1)
+		location := result.Locations[0].PhysicalLocation
+		require.NotNil(t, location.Region)
+		summaries = append(summaries, resultSummary{
+			RuleID: result.RuleID,
+			Level:  result.Level,
+			URI:    location.ArtifactLocation.URI,
+			Line:   location.Region.StartLine,
+			Column: location.Region.StartColumn,
+		})
+	}
+
+	assert.Equal(t, []resultSummary{
+		{RuleID: sarifRuleSDKUpdate, Level: "note", URI: "pubspec.yaml", Line: 3, Column: 8},
+		{RuleID: sarifRuleDependencyUpdate, Level: "warning", URI: "pubspec.yaml", Line: 5, Column: 9},
+		{RuleID: sarifRuleDependencyUpdate, Level: "note", URI: "pubspec.yaml", Line: 7, Column: 14},
+		{RuleID: sarifRuleCheckFailed, Level: "warning", URI: "pubspec.yaml", Line: 9, Column: 12},
+	}, summaries)
+	assert.Equal(t, "http can be updated from 1.1.0 to 2.0.0 (major)", log.Runs[0].Results[1].Message.Text)
+	assert.Equal(t, "missing could not be checked: HTTP 404 Not Found", log.Runs[0].Results[3].Message.Text)
+}
+
+func TestSARIFDisplayService_PrintUpdate_MissingPubspec(t *testing.T) {
+	var output bytes.Buffer
+	displayService := &SARIFDisplayService{Output: &output, PubspecFilePath: filepath.Join(t.TempDir(), "pubspec.yaml")}
+	displayService.PrintUpdate(&models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "1.2.0"},
+		},
+	})
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(output.Bytes(), &log))
+
+	// Results still point to the file, just not to a line
+	require.Len(t, log.Runs[0].Results, 1)
+	assert.Nil(t, log.Runs[0].Results[0].Locations[0].PhysicalLocation.Region)
+}
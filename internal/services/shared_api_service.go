@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+// SharedAPIService wraps an API service so the SDK release and every package
+// are fetched at most once, however many projects look them up. Concurrent
+// lookups of the same data wait for the first one and share its result,
+// including its error.
+type SharedAPIService struct {
+	APIService APIServiceInterface
+
+	mutex sync.Mutex
+	calls map[string]*sharedCall
+}
+
+// sharedCall is a lookup that is in progress or done
+type sharedCall struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// NewSharedAPIService creates a new instance of SharedAPIService
+func NewSharedAPIService(apiService APIServiceInterface) *SharedAPIService {
+	return &SharedAPIService{
+		APIService: apiService,
+	}
+}
+
+// GetSDKRelease implements the APIServiceInterface
+func (s *SharedAPIService) GetSDKRelease(ctx context.Context) (*models.SDKReleaseWrapper, error) {
+	return getShared(s, SDK_RELEASE_CACHE_KEY, func() (*models.SDKReleaseWrapper, error) {
+		return s.APIService.GetSDKRelease(ctx)
+	})
+}
+
+// GetPackage implements the APIServiceInterface
+func (s *SharedAPIService) GetPackage(ctx context.Context, packageName string) (*models.PackageWrapper, error) {
+	return getShared(s, PACKAGE_CACHE_PREFIX+packageName, func() (*models.PackageWrapper, error) {
+		return s.APIService.GetPackage(ctx, packageName)
+	})
+}
+
+// GetHostedPackage implements the APIServiceInterface
+func (s *SharedAPIService) GetHostedPackage(ctx context.Context, hostedURL, packageName string) (*models.PackageWrapper, error) {
+	if hostedURL == "" {
+		return s.GetPackage(ctx, packageName)
+	}
+
+	return getShared(s, PACKAGE_CACHE_PREFIX+hostedURL+" "+packageName, func() (*models.PackageWrapper, error) {
+		return s.APIService.GetHostedPackage(ctx, hostedURL, packageName)
+	})
+}
+
+// getShared returns the result of the lookup with the given key, calling fetch
+// only for the first lookup of the key
+func getShared[T any](s *SharedAPIService, key string, fetch func() (T, error)) (T, error) {
+	s.mutex.Lock()
+	if s.calls == nil {
+		s.calls = make(map[string]*sharedCall)
+	}
+	call, ok := s.calls[key]
+	if !ok {
+		call = &sharedCall{done: make(chan struct{})}
+		s.calls[key] = call
+	}
+	s.mutex.Unlock()
+
+	if ok {
+		<-call.done
+	} else {
+		call.value, call.err = fetch()
+		close(call.done)
+	}
+
+	value, _ := call.value.(T)
+	return value, call.err
+}
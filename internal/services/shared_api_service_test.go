@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sunderee/puby/internal/models"
+)
+
+func TestSharedAPIService(t *testing.T) {
+	var sdkCalls, packageCalls, hostedCalls atomic.Int32
+	service := NewSharedAPIService(&MockAPIService{
+		GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+			sdkCalls.Add(1)
+			return &models.SDKReleaseWrapper{}, nil
+		},
+		GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+			packageCalls.Add(1)
+			if packageName == "missing" {
+				return nil, errors.New("not found")
+			}
+			return &models.PackageWrapper{Name: packageName}, nil
+		},
+		GetHostedPackageFunc: func(hostedURL, packageName string) (*models.PackageWrapper, error) {
+			hostedCalls.Add(1)
+			return &models.PackageWrapper{Name: packageName}, nil
+		},
+	})
+
+	// Look everything up from several goroutines at once, like projects
+	// checked side by side would
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sdkRelease, err := service.GetSDKRelease(context.Background())
+			assert.NoError(t, err)
+			assert.NotNil(t, sdkRelease)
+
+			packageWrapper, err := service.GetPackage(context.Background(), "http")
+			assert.NoError(t, err)
+			assert.Equal(t, "http", packageWrapper.Name)
+
+			packageWrapper, err = service.GetHostedPackage(context.Background(), "", "http")
+			assert.NoError(t, err)
+			assert.Equal(t, "http", packageWrapper.Name)
+
+			_, err = service.GetHostedPackage(context.Background(), "https://pub.example.com", "http")
+			assert.NoError(t, err)
+
+			packageWrapper, err = service.GetPackage(context.Background(), "missing")
+			assert.EqualError(t, err, "not found")
+			assert.Nil(t, packageWrapper)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), sdkCalls.Load())
+	assert.Equal(t, int32(2), packageCalls.Load())
+	assert.Equal(t, int32(1), hostedCalls.Load())
+}
@@ -1,18 +1,34 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/sunderee/puby/internal/config"
 	"github.com/sunderee/puby/internal/models"
 	"github.com/sunderee/puby/internal/parsers"
+	"github.com/sunderee/puby/internal/semver"
 )
 
 type UpdateService struct {
 	Config        *config.CLIConfig
 	PubspecParser parsers.PubspecParserInterface
 	APIService    APIServiceInterface
+
+	// LockfileParser reads pubspec.lock to report the resolved versions. If it
+	// is nil, only the constraints in pubspec.yaml are considered.
+	LockfileParser parsers.LockfileParserInterface
+
+	// GitService is used to check git dependencies. If it is nil, git
+	// dependencies are not checked.
+	GitService GitServiceInterface
+
+	// ProjectDir is the directory of the pubspec.yaml, which path dependencies
+	// are relative to. If it is empty, path dependencies are not followed.
+	ProjectDir string
 }
 
 func NewUpdateService(pubspecParser parsers.PubspecParserInterface, apiService APIServiceInterface) *UpdateService {
@@ -22,43 +38,31 @@ func NewUpdateService(pubspecParser parsers.PubspecParserInterface, apiService A
 	}
 }
 
+// CheckForUpdates checks the pubspec for SDK and dependency updates
 func (s *UpdateService) CheckForUpdates() (*models.Update, error) {
+	return s.CheckForUpdatesWithContext(context.Background())
+}
+
+// CheckForUpdatesWithContext checks the pubspec for SDK and dependency updates,
+// aborting running and outstanding package lookups once the context is
+// cancelled
+func (s *UpdateService) CheckForUpdatesWithContext(ctx context.Context) (*models.Update, error) {
 	// Open the pubspec.yaml file and parse it
 	pubspec, err := s.PubspecParser.Parse()
 	if err != nil {
 		return nil, err
 	}
 
-	// Get the latest SDK release
-	sdkRelease, err := s.APIService.GetSDKRelease()
-	if err != nil {
+	// Get the latest SDK release. When offline, the SDKs are skipped if the
+	// release data was never cached.
+	sdkRelease, err := s.APIService.GetSDKRelease(ctx)
+	if err != nil && !errors.Is(err, ErrNotCached) {
 		return nil, err
 	}
 
-	// Check if there's an update needed for the Dart and Flutter SDKs
-	isDartSDKUpdateNeeded := s.isDartSDKUpdateNeeded(sdkRelease, pubspec)
-	isFlutterSDKUpdateNeeded := s.isFlutterSDKUpdateNeeded(sdkRelease, pubspec)
-
 	var environmentUpdate *models.EnvironmentUpdate = nil
-	if isDartSDKUpdateNeeded || isFlutterSDKUpdateNeeded {
-		// Update is needed for either one of them...
-		var dartSDKVersion *string = nil
-		var flutterSDKVersion *string = nil
-
-		if isDartSDKUpdateNeeded {
-			// Get the latest Dart SDK version (if different from the current one)
-			dartSDKVersion = s.dartSDKToUpdateTo(sdkRelease, pubspec)
-		}
-
-		if isFlutterSDKUpdateNeeded {
-			// Get the latest Flutter SDK version
-			flutterSDKVersion = s.flutterSDKToUpdateTo(sdkRelease, pubspec)
-		}
-
-		environmentUpdate = &models.EnvironmentUpdate{
-			DartSDKVersion:    dartSDKVersion,
-			FlutterSDKVersion: flutterSDKVersion,
-		}
+	if sdkRelease != nil {
+		environmentUpdate = s.produceEnvironmentUpdate(sdkRelease, pubspec)
 	}
 
 	// Check if there's a conflict between included and excluded packages
@@ -66,30 +70,171 @@ func (s *UpdateService) CheckForUpdates() (*models.Update, error) {
 		return nil, errors.New("there's a conflict between included and excluded packages")
 	}
 
-	// Produce a slice of dependencies to update
+	// Read the resolved versions from pubspec.lock, if there is one
+	lockfile, err := s.loadLockfile()
+	if err != nil {
+		return nil, err
+	}
+
+	// Produce a slice of dependencies to update, followed by the indirect ones
+	// if they are checked as well
 	dependenciesToUpdate := s.produceSliceOfDependenciesToUpdate(pubspec)
+	var transitiveDependencies []models.Dependency
+	if s.isTransitiveCheckEnabled() {
+		transitiveDependencies = s.produceSliceOfTransitiveDependencies(lockfile)
+	}
+	dependenciesToFetch := append(append([]models.Dependency{}, dependenciesToUpdate...), transitiveDependencies...)
 
-	// Fetch latest dependency data from API for each dependency
-	var dependencyDataFromAPI []*models.PackageWrapper
-	for _, dependency := range dependenciesToUpdate {
-		data, err := s.APIService.GetPackage(dependency)
-		if err != nil {
-			return nil, err
-		}
+	// Fetch latest dependency data from API for each dependency. Packages that
+	// can't be fetched are reported as failures instead of failing the check.
+	packageData, packageErrors := s.fetchPackages(ctx, dependenciesToFetch)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-		dependencyDataFromAPI = append(dependencyDataFromAPI, data)
+	// Packages that dependency_overrides replaces with a local package are
+	// checked against it as well, so one that isn't published yet is not a
+	// failure
+	overrides := s.pathOverrides(pubspec)
+	var failures []models.PackageFailure
+	var unknown []string
+	for i, dependency := range dependenciesToFetch {
+		if overrides[dependency.Name] != "" && isNotPublished(packageErrors[i]) {
+			continue
+		}
+		if errors.Is(packageErrors[i], ErrNotCached) {
+			unknown = append(unknown, dependency.Name)
+			continue
+		}
+		if packageErrors[i] != nil {
+			failures = append(failures, newPackageFailure(dependency.Name, packageErrors[i]))
+		}
 	}
 
 	// Produce a slice of dependency updates
-	dependencyUpdates := s.produceSliceOfDependencyUpdates(dependenciesToUpdate, dependencyDataFromAPI)
+	directCount := len(dependenciesToUpdate)
+	dependencyUpdates, heldBack := s.produceSliceOfDependencyUpdates(dependenciesToUpdate, packageData[:directCount])
+	transitiveUpdates := s.produceSliceOfTransitiveUpdates(transitiveDependencies, packageData[directCount:])
+
+	// Check git dependencies against the tags of their repositories. They
+	// can't be checked offline, as nothing about them is cached.
+	var checked []models.Dependency
+	checked = append(checked, dependenciesToUpdate...)
+	if s.GitService != nil {
+		gitDependencies := s.produceSliceOfGitDependencies(pubspec)
+		checked = append(checked, gitDependencies...)
+		if s.isOffline() {
+			for _, dependency := range gitDependencies {
+				unknown = append(unknown, dependency.Name)
+			}
+		} else {
+			gitUpdates, gitFailures := s.checkGitDependencies(ctx, gitDependencies)
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			gitUpdates, gitHeldBack := holdBackUpdates(gitUpdates, pubspec.Annotations)
+			dependencyUpdates = append(dependencyUpdates, gitUpdates...)
+			heldBack = append(heldBack, gitHeldBack...)
+			failures = append(failures, gitFailures...)
+		}
+	}
+
+	// Check dependencies on local packages against the versions in their
+	// pubspec.yaml. They are read from disk, so they are checked offline too.
+	pathDependencies := s.produceSliceOfPathDependencies(pubspec)
+	for _, dependency := range pathDependencies {
+		if overrides[dependency.Name] == "" {
+			checked = append(checked, dependency)
+		}
+	}
+	pathUpdates, pathFailures := s.checkPathDependencies(pathDependencies)
+	pathUpdates, pathHeldBack := holdBackUpdates(pathUpdates, pubspec.Annotations)
+	dependencyUpdates = append(dependencyUpdates, pathUpdates...)
+	heldBack = append(heldBack, pathHeldBack...)
+	failures = append(failures, pathFailures...)
+
+	applyResolvedVersions(dependencyUpdates, lockfile)
 
 	// Return the update object
 	return &models.Update{
 		EnvironmentUpdate: environmentUpdate,
 		DependencyUpdates: dependencyUpdates,
+		TransitiveUpdates: transitiveUpdates,
+		HeldBack:          heldBack,
+		Checked:           checked,
+		Failures:          failures,
+		Unknown:           unknown,
+		SDKStatusUnknown:  sdkRelease == nil,
 	}, nil
 }
 
+// produceEnvironmentUpdate checks if there's an update needed for the Dart and
+// Flutter SDKs, returning nil if both are up to date
+func (s *UpdateService) produceEnvironmentUpdate(sdkRelease *models.SDKReleaseWrapper, pubspec *models.Pubspec) *models.EnvironmentUpdate {
+	isDartSDKUpdateNeeded := s.isDartSDKUpdateNeeded(sdkRelease, pubspec)
+	isFlutterSDKUpdateNeeded := s.isFlutterSDKUpdateNeeded(sdkRelease, pubspec)
+	if !isDartSDKUpdateNeeded && !isFlutterSDKUpdateNeeded {
+		return nil
+	}
+
+	// Update is needed for either one of them...
+	var dartSDKVersion *string = nil
+	var flutterSDKVersion *string = nil
+
+	if isDartSDKUpdateNeeded {
+		// Get the latest Dart SDK version (if different from the current one)
+		dartSDKVersion = s.dartSDKToUpdateTo(sdkRelease, pubspec)
+	}
+
+	if isFlutterSDKUpdateNeeded {
+		// Get the latest Flutter SDK version
+		flutterSDKVersion = s.flutterSDKToUpdateTo(sdkRelease, pubspec)
+	}
+
+	environmentUpdate := &models.EnvironmentUpdate{
+		DartSDKVersion:    dartSDKVersion,
+		FlutterSDKVersion: flutterSDKVersion,
+	}
+	if s.isOffline() {
+		environmentUpdate.CachedAt = sdkRelease.FetchedAt
+	}
+
+	return environmentUpdate
+}
+
+// isNotPublished reports whether a package lookup failed because the package
+// repository doesn't know the package, or it was never cached
+func isNotPublished(err error) bool {
+	var statusError *HTTPStatusError
+	if errors.As(err, &statusError) && statusError.StatusCode == http.StatusNotFound {
+		return true
+	}
+
+	return errors.Is(err, ErrNotCached)
+}
+
+// isOffline reports whether updates are checked against cached data only
+func (s *UpdateService) isOffline() bool {
+	return s.Config != nil && s.Config.Offline != nil && *s.Config.Offline
+}
+
+// newPackageFailure describes a failed package lookup, extracting the HTTP
+// status code from the error if there is one
+func newPackageFailure(packageName string, err error) models.PackageFailure {
+	failure := models.PackageFailure{
+		Name:  packageName,
+		Cause: err,
+	}
+
+	var statusError *HTTPStatusError
+	if errors.As(err, &statusError) {
+		failure.StatusCode = statusError.StatusCode
+	}
+
+	return failure
+}
+
 func (s *UpdateService) isDartSDKUpdateNeeded(sdkRelease *models.SDKReleaseWrapper, pubspec *models.Pubspec) bool {
 	var latestVersionHash string
 	if s.Config.UseBetaSDKVersions != nil && *s.Config.UseBetaSDKVersions {
@@ -106,10 +251,12 @@ func (s *UpdateService) isDartSDKUpdateNeeded(sdkRelease *models.SDKReleaseWrapp
 		}
 	}
 
-	cleanedCurrentVersion := cleanupVersionString(*pubspec.Environment.DartSDKVersion)
-	cleanedLatestVersion := cleanupVersionString(latestStableVersionDartSDK)
+	if pubspec.Environment == nil || pubspec.Environment.DartSDKVersion == nil {
+		return false
+	}
 
-	return cleanedCurrentVersion != cleanedLatestVersion
+	_, isOutdated := compareWithConstraint(*pubspec.Environment.DartSDKVersion, latestStableVersionDartSDK)
+	return isOutdated
 }
 
 func (s *UpdateService) dartSDKToUpdateTo(sdkRelease *models.SDKReleaseWrapper, pubspec *models.Pubspec) *string {
@@ -146,10 +293,12 @@ func (s *UpdateService) isFlutterSDKUpdateNeeded(sdkRelease *models.SDKReleaseWr
 			}
 		}
 
-		cleanedCurrentVersion := cleanupVersionString(*pubspec.Environment.FlutterSDKVersion)
-		cleanedLatestVersion := cleanupVersionString(latestStableVersionFlutterSDK)
+		if pubspec.Environment == nil || pubspec.Environment.FlutterSDKVersion == nil {
+			return false
+		}
 
-		return cleanedCurrentVersion != cleanedLatestVersion
+		_, isOutdated := compareWithConstraint(*pubspec.Environment.FlutterSDKVersion, latestStableVersionFlutterSDK)
+		return isOutdated
 	}
 
 	return false
@@ -194,8 +343,50 @@ func (s *UpdateService) isThereAConflictBetweenIncludedAndExcludedPackages(pubsp
 	return false
 }
 
-func (s *UpdateService) produceSliceOfDependenciesToUpdate(pubspec *models.Pubspec) []string {
-	var dependenciesToUpdate []string
+func (s *UpdateService) produceSliceOfDependenciesToUpdate(pubspec *models.Pubspec) []models.Dependency {
+	var dependenciesToUpdate []models.Dependency
+
+	// Process the dependencies of every section, looking each package up only
+	// once per package repository
+	seen := make(map[string]bool)
+	for _, section := range models.DependencySections {
+		for dependencyName, dependencyValue := range pubspec.DependenciesIn(section) {
+			// Skip dependencies that aren't hosted (like SDK references)
+			dependency := models.ParseDependency(dependencyName, section, dependencyValue)
+			if dependency.Source != models.HostedSource {
+				continue
+			}
+
+			if seen[packageKey(dependency)] {
+				continue
+			}
+			seen[packageKey(dependency)] = true
+
+			if s.isPackageSelected(dependencyName) {
+				dependenciesToUpdate = append(dependenciesToUpdate, dependency)
+			}
+		}
+	}
+
+	// Sort so lookups and results come out in the same order on every run
+	sort.Slice(dependenciesToUpdate, func(i, j int) bool {
+		if dependenciesToUpdate[i].Name != dependenciesToUpdate[j].Name {
+			return dependenciesToUpdate[i].Name < dependenciesToUpdate[j].Name
+		}
+		return dependenciesToUpdate[i].HostedURL < dependenciesToUpdate[j].HostedURL
+	})
+
+	return dependenciesToUpdate
+}
+
+// isPackageSelected reports whether a package passes the include and exclude
+// filters. Includes take precedence; without filters, every package is selected.
+func (s *UpdateService) isPackageSelected(packageName string) bool {
+	// Packages ignored by their rule are never selected
+	if s.packageRule(packageName).Ignore {
+		return false
+	}
+
 	var includedPackages, excludedPackages []string
 
 	// Get include and exclude packages if they exist
@@ -206,90 +397,258 @@ func (s *UpdateService) produceSliceOfDependenciesToUpdate(pubspec *models.Pubsp
 		excludedPackages = *s.Config.ExcludePackages
 	}
 
-	// Process all dependencies
-	for dependencyName, dependencyVersion := range pubspec.Dependencies {
-		// Skip non-string versions (like SDK references)
-		if _, ok := dependencyVersion.(string); !ok {
-			continue
+	// If includes are specified, only select if in the includes list
+	if len(includedPackages) > 0 {
+		for _, includedPackage := range includedPackages {
+			if strings.Contains(packageName, includedPackage) {
+				return true
+			}
 		}
+		return false
+	}
 
-		// If no includes or excludes are specified, add all dependencies
-		if len(includedPackages) == 0 && len(excludedPackages) == 0 {
-			dependenciesToUpdate = append(dependenciesToUpdate, dependencyName)
-			continue
+	// Otherwise, select unless in the excludes list
+	for _, excludedPackage := range excludedPackages {
+		if strings.Contains(packageName, excludedPackage) {
+			return false
 		}
+	}
 
-		// If includes are specified, only add if in the includes list
-		if len(includedPackages) > 0 {
-			for _, includedPackage := range includedPackages {
-				if strings.Contains(dependencyName, includedPackage) {
-					dependenciesToUpdate = append(dependenciesToUpdate, dependencyName)
-					break
-				}
-			}
-			continue
-		}
+	return true
+}
 
-		// If excludes are specified, add unless in the excludes list
-		if len(excludedPackages) > 0 {
-			isExcluded := false
-			for _, excludedPackage := range excludedPackages {
-				if strings.Contains(dependencyName, excludedPackage) {
-					isExcluded = true
-					break
-				}
-			}
-			if !isExcluded {
-				dependenciesToUpdate = append(dependenciesToUpdate, dependencyName)
-			}
-		}
+// packageRule returns the rule configured for a package, or the zero rule if
+// there is none
+func (s *UpdateService) packageRule(packageName string) config.PackageRule {
+	if s.Config == nil {
+		return config.PackageRule{}
 	}
 
-	return dependenciesToUpdate
+	return s.Config.PackageRules[packageName]
 }
 
-func (s *UpdateService) produceSliceOfDependencyUpdates(dependenciesToUpdate []string, dependencyDataFromAPI []*models.PackageWrapper) []models.DependencyUpdate {
+// produceSliceOfDependencyUpdates compares the dependencies with the data
+// fetched for them. The data slice is index-aligned with the dependencies and
+// holds nil for packages that could not be fetched. Updates the annotations in
+// pubspec.yaml don't allow are returned separately as held back.
+func (s *UpdateService) produceSliceOfDependencyUpdates(dependenciesToUpdate []models.Dependency, dependencyDataFromAPI []*models.PackageWrapper) ([]models.DependencyUpdate, []models.HeldBackUpdate) {
 	var dependencyUpdates []models.DependencyUpdate
+	var heldBack []models.HeldBackUpdate
 
 	// Get the pubspec to extract current versions
 	pubspec, err := s.PubspecParser.Parse()
 	if err != nil {
-		return []models.DependencyUpdate{}
+		return []models.DependencyUpdate{}, nil
 	}
 
-	// Map package names to their API data for easier lookup
+	// Map packages to their API data for easier lookup
 	packageDataMap := make(map[string]*models.PackageWrapper)
-	for _, packageData := range dependencyDataFromAPI {
-		packageDataMap[packageData.Name] = packageData
-	}
-
-	// Create dependency updates
-	for _, dependencyName := range dependenciesToUpdate {
-		// Get current version from pubspec
-		if currentVersion, ok := pubspec.Dependencies[dependencyName]; ok {
-			// Only handle string versions
-			if currentVersionStr, ok := currentVersion.(string); ok {
-				// Clean up the version string (remove ^, ~, >=, etc.)
-				cleanedCurrentVersion := cleanupVersionString(currentVersionStr)
-
-				// Get the latest version from API data
-				if packageData, exists := packageDataMap[dependencyName]; exists {
-					latestVersion := packageData.LatestVersion.Version
-
-					// Only add to updates if the versions are different
-					if cleanedCurrentVersion != latestVersion {
-						dependencyUpdates = append(dependencyUpdates, models.DependencyUpdate{
-							Name:           dependencyName,
-							CurrentVersion: cleanedCurrentVersion,
-							LatestVersion:  latestVersion,
-						})
-					}
+	for i, packageData := range dependencyDataFromAPI {
+		if packageData != nil {
+			packageDataMap[packageKey(dependenciesToUpdate[i])] = packageData
+		}
+	}
+
+	// Create dependency updates, section by section, since the same package may
+	// be declared in more than one of them
+	for _, section := range models.DependencySections {
+		dependencies := pubspec.DependenciesIn(section)
+
+		for _, dependencyToUpdate := range dependenciesToUpdate {
+			// Get the current declaration from pubspec
+			value, ok := dependencies[dependencyToUpdate.Name]
+			if !ok {
+				continue
+			}
+
+			// Only handle hosted dependencies from the same package repository
+			dependency := models.ParseDependency(dependencyToUpdate.Name, section, value)
+			if dependency.Source != models.HostedSource || packageKey(dependency) != packageKey(dependencyToUpdate) {
+				continue
+			}
+
+			// Get the version to update to from API data
+			packageData, exists := packageDataMap[packageKey(dependency)]
+			if !exists {
+				continue
+			}
+
+			// Only report an update if the latest version is newer than the constraint
+			newDependencyUpdate := func(latestVersion string) (models.DependencyUpdate, bool) {
+				currentVersion, isOutdated := compareWithConstraint(dependency.Constraint, latestVersion)
+				if latestVersion == "" || !isOutdated {
+					return models.DependencyUpdate{}, false
+				}
+
+				dependencyUpdate := models.DependencyUpdate{
+					Name:           dependency.Name,
+					CurrentVersion: currentVersion,
+					LatestVersion:  latestVersion,
+					Constraint:     dependency.Constraint,
+					Section:        section,
+					HostedURL:      dependency.HostedURL,
+				}
+				if s.isOffline() {
+					dependencyUpdate.CachedAt = packageData.FetchedAt
 				}
+
+				return dependencyUpdate, true
+			}
+
+			// Hold back the update if its annotation doesn't allow it, proposing
+			// the newest version the annotation still allows instead
+			latestVersion := s.versionToUpdateTo(dependency.Constraint, packageData, models.DependencyAnnotation{})
+			if annotation := pubspec.Annotations[dependency.Name]; !annotation.Allows(latestVersion) {
+				if dependencyUpdate, ok := newDependencyUpdate(latestVersion); ok {
+					heldBack = append(heldBack, models.HeldBackUpdate{DependencyUpdate: dependencyUpdate, Reason: annotation.Reason()})
+				}
+				latestVersion = s.versionToUpdateTo(dependency.Constraint, packageData, annotation)
+			}
+
+			if dependencyUpdate, ok := newDependencyUpdate(latestVersion); ok {
+				dependencyUpdates = append(dependencyUpdates, dependencyUpdate)
 			}
 		}
 	}
 
-	return dependencyUpdates
+	return dependencyUpdates, heldBack
+}
+
+// holdBackUpdates moves the updates that the annotations in pubspec.yaml don't
+// allow from the updates to the held back ones
+func holdBackUpdates(dependencyUpdates []models.DependencyUpdate, annotations map[string]models.DependencyAnnotation) ([]models.DependencyUpdate, []models.HeldBackUpdate) {
+	var allowed []models.DependencyUpdate
+	var heldBack []models.HeldBackUpdate
+	for _, dependencyUpdate := range dependencyUpdates {
+		annotation := annotations[dependencyUpdate.Name]
+		if annotation.Allows(dependencyUpdate.LatestVersion) {
+			allowed = append(allowed, dependencyUpdate)
+			continue
+		}
+
+		heldBack = append(heldBack, models.HeldBackUpdate{DependencyUpdate: dependencyUpdate, Reason: annotation.Reason()})
+	}
+
+	return allowed, heldBack
+}
+
+// packageKey identifies a package by its name and the repository it's hosted on
+func packageKey(dependency models.Dependency) string {
+	return dependency.HostedURL + " " + dependency.Name
+}
+
+// versionToUpdateTo picks the newest published version of a package that the
+// configured update policy, the package's rule and the annotation allow, given
+// the dependency's current constraint. Retracted versions are never picked and
+// pre-releases only if the constraint itself points at one. It returns an
+// empty string if no version qualifies.
+func (s *UpdateService) versionToUpdateTo(constraint string, packageData *models.PackageWrapper, annotation models.DependencyAnnotation) string {
+	policy := config.UpdatePolicyMajor
+	if s.Config != nil && s.Config.UpdatePolicy != nil {
+		policy = *s.Config.UpdatePolicy
+	}
+
+	rule := s.packageRule(packageData.Name)
+
+	parsedConstraint, err := semver.ParseConstraint(constraint)
+	if err != nil {
+		// Without a constraint to measure the update against, only the major
+		// policy can tell that the latest version is allowed
+		if policy != config.UpdatePolicyMajor || !annotation.Allows(packageData.LatestVersion.Version) {
+			return ""
+		}
+		latestVersion, err := semver.Parse(packageData.LatestVersion.Version)
+		if err != nil || !rule.Allows(latestVersion) {
+			return ""
+		}
+		return packageData.LatestVersion.Version
+	}
+
+	// Without the full version list, the latest version is the only candidate.
+	// pub.dev only reports a pre-release as the latest version if the package
+	// has no stable release, so it is taken as is.
+	candidates := packageData.Versions
+	allowPreReleases := parsedConstraint.Min != nil && parsedConstraint.Min.IsPreRelease()
+	if len(candidates) == 0 {
+		candidates = []models.Package{packageData.LatestVersion}
+		allowPreReleases = true
+	}
+
+	var best *semver.Version
+	for _, candidate := range candidates {
+		if candidate.Retracted {
+			continue
+		}
+
+		version, err := semver.Parse(candidate.Version)
+		if err != nil {
+			continue
+		}
+		if version.IsPreRelease() && !allowPreReleases {
+			continue
+		}
+		if !isAllowedByPolicy(policy, parsedConstraint, version) || !rule.Allows(version) || !annotation.Allows(version.String()) {
+			continue
+		}
+
+		if best == nil || version.GreaterThan(*best) {
+			best = &version
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+
+	return best.String()
+}
+
+// isAllowedByPolicy reports whether the update policy allows moving a
+// dependency with the given constraint to the version. As in pub, a new minor
+// version of a 0.x release is a breaking change, so only the major policy
+// allows it. Constraints without a lower bound have nothing to measure patch
+// and minor updates against, so only versions they already allow are accepted.
+func isAllowedByPolicy(policy config.UpdatePolicy, constraint semver.Constraint, version semver.Version) bool {
+	if policy == config.UpdatePolicyMajor {
+		return true
+	}
+
+	current := constraint.Min
+	if policy == config.UpdatePolicyResolvable || current == nil {
+		return constraint.Allows(version)
+	}
+
+	switch policy {
+	case config.UpdatePolicyPatch:
+		return version.Major == current.Major && version.Minor == current.Minor
+	case config.UpdatePolicyMinor:
+		return version.Major == current.Major && (current.Major != 0 || version.Minor == current.Minor)
+	}
+
+	return true
+}
+
+// compareWithConstraint reports whether the latest version is newer than the
+// version a constraint points at, i.e. its lower bound, and returns that
+// version. A constraint without a lower bound is outdated only if it does not
+// allow the latest version, and "any" is never outdated. If either side cannot
+// be parsed, the cleaned up strings are compared instead.
+func compareWithConstraint(constraint, latest string) (string, bool) {
+	latestVersion, latestErr := semver.Parse(latest)
+	parsedConstraint, constraintErr := semver.ParseConstraint(constraint)
+	if latestErr != nil || constraintErr != nil {
+		cleanedCurrentVersion := cleanupVersionString(constraint)
+		return cleanedCurrentVersion, cleanedCurrentVersion != cleanupVersionString(latest)
+	}
+
+	switch {
+	case parsedConstraint.Min != nil:
+		return parsedConstraint.Min.String(), latestVersion.GreaterThan(*parsedConstraint.Min)
+	case parsedConstraint.Max != nil:
+		return parsedConstraint.Max.String(), !parsedConstraint.Allows(latestVersion)
+	}
+
+	return constraint, false
 }
 
 func cleanupVersionString(input string) string {
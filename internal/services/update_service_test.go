@@ -1,11 +1,16 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/sunderee/puby/internal/config"
 	"github.com/sunderee/puby/internal/models"
 	"github.com/sunderee/puby/internal/parsers"
@@ -278,6 +283,276 @@ func TestUpdateService_CheckForUpdates(t *testing.T) {
 	}
 }
 
+func TestUpdateService_CheckForUpdates_HostedDependencies(t *testing.T) {
+	var hostedLookups []string
+	service := NewUpdateService(
+		&parsers.MockPubspecParser{
+			ParseFunc: func() (*models.Pubspec, error) {
+				return &models.Pubspec{
+					Environment: &models.PubspecEnvironment{DartSDKVersion: stringPtr("^3.0.0")},
+					Dependencies: map[string]any{
+						"http": "^1.1.0",
+						"internal_pkg": map[string]any{
+							"hosted":  "https://pub.example.com",
+							"version": "^2.0.0",
+						},
+					},
+					DependencyOverrides: map[string]any{
+						"http": map[string]any{
+							"hosted":  "https://mirror.example.com",
+							"version": "1.0.0",
+						},
+					},
+				}, nil
+			},
+		},
+		&MockAPIService{
+			GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+				return &models.SDKReleaseWrapper{}, nil
+			},
+			GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+				return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: "1.2.0"}}, nil
+			},
+			GetHostedPackageFunc: func(hostedURL, packageName string) (*models.PackageWrapper, error) {
+				hostedLookups = append(hostedLookups, hostedURL+" "+packageName)
+				version := "2.3.0"
+				if packageName == "http" {
+					version = "1.1.5"
+				}
+				return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: version}}, nil
+			},
+		},
+	)
+	service.Config = &config.CLIConfig{Concurrency: intPtr(1)}
+
+	update, err := service.CheckForUpdates()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://mirror.example.com http", "https://pub.example.com internal_pkg"}, hostedLookups)
+	assert.Equal(t, []models.DependencyUpdate{
+		{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "1.2.0", Constraint: "^1.1.0", Section: models.DependenciesSection},
+		{Name: "internal_pkg", CurrentVersion: "2.0.0", LatestVersion: "2.3.0", Constraint: "^2.0.0", Section: models.DependenciesSection, HostedURL: "https://pub.example.com"},
+		{Name: "http", CurrentVersion: "1.0.0", LatestVersion: "1.1.5", Constraint: "1.0.0", Section: models.DependencyOverridesSection, HostedURL: "https://mirror.example.com"},
+	}, update.DependencyUpdates)
+}
+
+func TestUpdateService_CheckForUpdates_Offline(t *testing.T) {
+	cachedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := NewUpdateService(
+		&parsers.MockPubspecParser{
+			ParseFunc: func() (*models.Pubspec, error) {
+				return &models.Pubspec{
+					Environment: &models.PubspecEnvironment{DartSDKVersion: stringPtr("^3.0.0")},
+					Dependencies: map[string]any{
+						"http":     "^1.0.0",
+						"uncached": "^1.0.0",
+					},
+				}, nil
+			},
+		},
+		&MockAPIService{
+			GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+				return nil, ErrNotCached
+			},
+			GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+				if packageName == "uncached" {
+					return nil, ErrNotCached
+				}
+				return &models.PackageWrapper{
+					Name:          packageName,
+					LatestVersion: models.Package{Version: "1.2.0"},
+					FetchedAt:     cachedAt,
+				}, nil
+			},
+		},
+	)
+	service.Config = &config.CLIConfig{Offline: boolPtr(true)}
+
+	update, err := service.CheckForUpdates()
+
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Update{
+		DependencyUpdates: []models.DependencyUpdate{
+			{
+				Name:           "http",
+				CurrentVersion: "1.0.0",
+				LatestVersion:  "1.2.0",
+				Constraint:     "^1.0.0",
+				Section:        models.DependenciesSection,
+				CachedAt:       cachedAt,
+			},
+		},
+		Checked: []models.Dependency{
+			{Name: "http", Section: models.DependenciesSection, Source: models.HostedSource, Constraint: "^1.0.0"},
+			{Name: "uncached", Section: models.DependenciesSection, Source: models.HostedSource, Constraint: "^1.0.0"},
+		},
+		Unknown:          []string{"uncached"},
+		SDKStatusUnknown: true,
+	}, update)
+}
+
+func TestUpdateService_CheckForUpdates_GitDependencies(t *testing.T) {
+	pubspec := &models.Pubspec{
+		Dependencies: map[string]any{
+			"forked": map[string]any{
+				"git": map[string]any{
+					"url": "https://example.com/forked.git",
+					"ref": "v1.2.0",
+				},
+			},
+			"tracking": map[string]any{
+				"git": "https://example.com/tracking.git",
+			},
+			"broken": map[string]any{
+				"git": map[string]any{
+					"url": "https://example.com/broken.git",
+					"ref": "1.0.0",
+				},
+			},
+		},
+	}
+	apiService := &MockAPIService{
+		GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+			return &models.SDKReleaseWrapper{}, nil
+		},
+	}
+
+	var listedURLs []string
+	gitService := &MockGitService{
+		ListRemoteRefsFunc: func(ctx context.Context, repositoryURL string) (*models.GitRefs, error) {
+			listedURLs = append(listedURLs, repositoryURL)
+			if repositoryURL == "https://example.com/broken.git" {
+				return nil, errors.New("git ls-remote failed: repository not found")
+			}
+			return &models.GitRefs{Tags: []string{"v1.2.0", "v1.3.0", "v2.0.0-dev.1"}, Branches: []string{"main"}}, nil
+		},
+	}
+
+	t.Run("newer tags are reported", func(t *testing.T) {
+		listedURLs = nil
+		service := NewUpdateService(&parsers.MockPubspecParser{ParseFunc: func() (*models.Pubspec, error) { return pubspec, nil }}, apiService)
+		service.GitService = gitService
+		service.Config = &config.CLIConfig{Concurrency: intPtr(1)}
+
+		update, err := service.CheckForUpdates()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"https://example.com/broken.git", "https://example.com/forked.git", "https://example.com/tracking.git"}, listedURLs)
+		assert.Equal(t, []models.DependencyUpdate{
+			{Name: "forked", CurrentVersion: "v1.2.0", LatestVersion: "v1.3.0", Section: models.DependenciesSection, Source: models.GitSource},
+		}, update.DependencyUpdates)
+		assert.Len(t, update.Failures, 1)
+		assert.Equal(t, "broken", update.Failures[0].Name)
+	})
+
+	t.Run("offline git dependencies are unknown", func(t *testing.T) {
+		listedURLs = nil
+		service := NewUpdateService(&parsers.MockPubspecParser{ParseFunc: func() (*models.Pubspec, error) { return pubspec, nil }}, apiService)
+		service.GitService = gitService
+		service.Config = &config.CLIConfig{Offline: boolPtr(true)}
+
+		update, err := service.CheckForUpdates()
+
+		assert.NoError(t, err)
+		assert.Empty(t, listedURLs)
+		assert.Equal(t, []string{"broken", "forked", "tracking"}, update.Unknown)
+	})
+}
+
+func TestUpdateService_CheckForUpdates_Lockfile(t *testing.T) {
+	pubspecParser := &parsers.MockPubspecParser{
+		ParseFunc: func() (*models.Pubspec, error) {
+			return &models.Pubspec{
+				Dependencies: map[string]any{"http": "^1.1.0"},
+			}, nil
+		},
+	}
+	lockfileParser := &parsers.MockLockfileParser{
+		ParseFunc: func() (*models.Lockfile, error) {
+			return &models.Lockfile{
+				Packages: map[string]models.LockedPackage{
+					"http": {
+						Dependency:  "direct main",
+						Description: map[string]any{"name": "http", "url": "https://pub.dev"},
+						Source:      "hosted",
+						Version:     "1.1.4",
+					},
+					"meta": {
+						Dependency:  "transitive",
+						Description: map[string]any{"name": "meta", "url": "https://pub.dev"},
+						Source:      "hosted",
+						Version:     "1.9.0",
+					},
+					"internal_meta": {
+						Dependency:  "transitive",
+						Description: map[string]any{"name": "internal_meta", "url": "https://pub.example.com"},
+						Source:      "hosted",
+						Version:     "2.0.0",
+					},
+					"flutter": {
+						Dependency:  "direct main",
+						Description: "flutter",
+						Source:      "sdk",
+						Version:     "0.0.0",
+					},
+				},
+			}, nil
+		},
+	}
+	apiService := &MockAPIService{
+		GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+			return &models.SDKReleaseWrapper{}, nil
+		},
+		GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+			versions := map[string]string{"http": "1.2.0", "meta": "1.11.0"}
+			return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: versions[packageName]}}, nil
+		},
+		GetHostedPackageFunc: func(hostedURL, packageName string) (*models.PackageWrapper, error) {
+			return &models.PackageWrapper{Name: packageName, LatestVersion: models.Package{Version: "2.0.0"}}, nil
+		},
+	}
+
+	t.Run("resolved versions are reported", func(t *testing.T) {
+		service := NewUpdateService(pubspecParser, apiService)
+		service.LockfileParser = lockfileParser
+		service.Config = &config.CLIConfig{}
+
+		update, err := service.CheckForUpdates()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.DependencyUpdate{
+			{Name: "http", CurrentVersion: "1.1.0", LatestVersion: "1.2.0", Constraint: "^1.1.0", ResolvedVersion: "1.1.4", Section: models.DependenciesSection},
+		}, update.DependencyUpdates)
+		assert.Empty(t, update.TransitiveUpdates)
+	})
+
+	t.Run("transitive packages are checked on request", func(t *testing.T) {
+		service := NewUpdateService(pubspecParser, apiService)
+		service.LockfileParser = lockfileParser
+		service.Config = &config.CLIConfig{IncludeTransitive: boolPtr(true)}
+
+		update, err := service.CheckForUpdates()
+
+		assert.NoError(t, err)
+		assert.Len(t, update.DependencyUpdates, 1)
+		assert.Equal(t, []models.DependencyUpdate{
+			{Name: "meta", CurrentVersion: "1.9.0", LatestVersion: "1.11.0", ResolvedVersion: "1.9.0"},
+		}, update.TransitiveUpdates)
+	})
+
+	t.Run("missing lockfile", func(t *testing.T) {
+		service := NewUpdateService(pubspecParser, apiService)
+		service.LockfileParser = parsers.NewLockfileParser(filepath.Join(t.TempDir(), "pubspec.lock"))
+		service.Config = &config.CLIConfig{IncludeTransitive: boolPtr(true)}
+
+		update, err := service.CheckForUpdates()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "", update.DependencyUpdates[0].ResolvedVersion)
+		assert.Empty(t, update.TransitiveUpdates)
+	})
+}
+
 // Helper function to create a pointer to a bool
 func boolPtr(b bool) *bool {
 	return &b
@@ -288,6 +563,73 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+func TestUpdateService_CheckForUpdates_Annotations(t *testing.T) {
+	versions := map[string][]string{
+		"intl":       {"0.18.0", "0.19.0", "0.20.2"},
+		"http":       {"0.13.0", "0.13.6", "1.2.0"},
+		"path":       {"1.8.0", "1.9.0"},
+		"collection": {"1.17.0", "1.18.0"},
+	}
+	apiService := &MockAPIService{
+		GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+			return nil, ErrNotCached
+		},
+		GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+			packageData := &models.PackageWrapper{Name: packageName}
+			for _, version := range versions[packageName] {
+				packageData.Versions = append(packageData.Versions, models.Package{Version: version})
+			}
+			packageData.LatestVersion = packageData.Versions[len(packageData.Versions)-1]
+			return packageData, nil
+		},
+	}
+
+	checkForUpdates := func(t *testing.T, content string) (*models.Update, error) {
+		pubspecPath := filepath.Join(t.TempDir(), "pubspec.yaml")
+		require.NoError(t, os.WriteFile(pubspecPath, []byte(content), 0644))
+
+		service := NewUpdateService(parsers.NewPubspecParser(pubspecPath), apiService)
+		service.Config = &config.CLIConfig{}
+		return service.CheckForUpdates()
+	}
+
+	t.Run("Held back", func(t *testing.T) {
+		update, err := checkForUpdates(t, `name: app
+dependencies:
+  intl: ^0.18.0 # puby:ignore pinned by flutter_localizations
+  http:
+    version: ^0.13.0 # puby:max 0.13
+  path: ^1.8.0 # puby:max 2
+  collection: ^1.17.0 # keep sorted
+`)
+		require.NoError(t, err)
+
+		assert.Equal(t, []models.DependencyUpdate{
+			{Name: "collection", CurrentVersion: "1.17.0", LatestVersion: "1.18.0", Constraint: "^1.17.0", Section: models.DependenciesSection},
+			{Name: "http", CurrentVersion: "0.13.0", LatestVersion: "0.13.6", Constraint: "^0.13.0", Section: models.DependenciesSection},
+			{Name: "path", CurrentVersion: "1.8.0", LatestVersion: "1.9.0", Constraint: "^1.8.0", Section: models.DependenciesSection},
+		}, update.DependencyUpdates)
+		assert.Equal(t, []models.HeldBackUpdate{
+			{
+				DependencyUpdate: models.DependencyUpdate{Name: "http", CurrentVersion: "0.13.0", LatestVersion: "1.2.0", Constraint: "^0.13.0", Section: models.DependenciesSection},
+				Reason:           "max 0.13",
+			},
+			{
+				DependencyUpdate: models.DependencyUpdate{Name: "intl", CurrentVersion: "0.18.0", LatestVersion: "0.20.2", Constraint: "^0.18.0", Section: models.DependenciesSection},
+				Reason:           "ignored: pinned by flutter_localizations",
+			},
+		}, update.HeldBack)
+	})
+
+	t.Run("Invalid annotation", func(t *testing.T) {
+		_, err := checkForUpdates(t, "dependencies:\n  http: ^0.13.0 # puby:max latest\n")
+		assert.ErrorContains(t, err, "invalid annotation on http in dependencies")
+
+		_, err = checkForUpdates(t, "dependencies:\n  http: ^0.13.0 # puby:skip\n")
+		assert.ErrorContains(t, err, `unknown instruction "puby:skip"`)
+	})
+}
+
 func TestUpdateService_IsDartSDKUpdateNeeded(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -519,6 +861,62 @@ func TestUpdateService_ProduceSliceOfDependenciesToUpdate(t *testing.T) {
 			},
 			expectedDependencies: []string{"http", "path", "flutter_svg"},
 		},
+		{
+			name:   "dev dependencies and overrides",
+			config: &config.CLIConfig{},
+			pubspec: &models.Pubspec{
+				Dependencies: map[string]any{
+					"http": "^0.13.3",
+				},
+				DevDependencies: map[string]any{
+					"lints": "^4.0.0",
+					"test":  "^1.25.0",
+				},
+				DependencyOverrides: map[string]any{
+					"http": "0.13.6",
+				},
+			},
+			expectedDependencies: []string{"http", "lints", "test"},
+		},
+		{
+			name:   "hosted dependencies",
+			config: &config.CLIConfig{},
+			pubspec: &models.Pubspec{
+				Dependencies: map[string]any{
+					"http": "^1.1.0",
+					"internal_pkg": map[string]any{
+						"hosted":  "https://pub.example.com",
+						"version": "^2.0.0",
+					},
+					"legacy_pkg": map[string]any{
+						"hosted": map[string]any{
+							"name": "legacy_pkg",
+							"url":  "https://pub.example.com",
+						},
+						"version": "^1.0.0",
+					},
+					"local_pkg": map[string]any{
+						"path": "../local_pkg",
+					},
+				},
+			},
+			expectedDependencies: []string{"http", "internal_pkg", "legacy_pkg"},
+		},
+		{
+			name: "exclude applies to dev dependencies",
+			config: &config.CLIConfig{
+				ExcludePackages: &[]string{"test"},
+			},
+			pubspec: &models.Pubspec{
+				Dependencies: map[string]any{
+					"http": "^0.13.3",
+				},
+				DevDependencies: map[string]any{
+					"test": "^1.25.0",
+				},
+			},
+			expectedDependencies: []string{"http"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -528,8 +926,13 @@ func TestUpdateService_ProduceSliceOfDependenciesToUpdate(t *testing.T) {
 			}
 			result := service.produceSliceOfDependenciesToUpdate(tt.pubspec)
 
+			var resultNames []string
+			for _, dependency := range result {
+				resultNames = append(resultNames, dependency.Name)
+			}
+
 			// Since the ordering is not guaranteed in maps
-			assert.ElementsMatch(t, tt.expectedDependencies, result)
+			assert.ElementsMatch(t, tt.expectedDependencies, resultNames)
 		})
 	}
 }
@@ -599,17 +1002,219 @@ func TestCleanupVersionString(t *testing.T) {
 	}
 }
 
+func TestUpdateService_VersionToUpdateTo(t *testing.T) {
+	packageData := &models.PackageWrapper{
+		Name: "http",
+		LatestVersion: models.Package{
+			Version: "2.1.0",
+		},
+		Versions: []models.Package{
+			{Version: "1.2.0"},
+			{Version: "1.2.5"},
+			{Version: "1.2.6", Retracted: true},
+			{Version: "1.4.0"},
+			{Version: "1.10.0"},
+			{Version: "2.0.0"},
+			{Version: "2.1.0"},
+			{Version: "3.0.0-dev.1"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		policy      *config.UpdatePolicy
+		constraint  string
+		packageData *models.PackageWrapper
+		expected    string
+	}{
+		{"default policy", nil, "^1.2.0", packageData, "2.1.0"},
+		{"major", updatePolicyPtr(config.UpdatePolicyMajor), "^1.2.0", packageData, "2.1.0"},
+		{"minor", updatePolicyPtr(config.UpdatePolicyMinor), "^1.2.0", packageData, "1.10.0"},
+		{"patch skips retracted", updatePolicyPtr(config.UpdatePolicyPatch), "^1.2.0", packageData, "1.2.5"},
+		{"resolvable", updatePolicyPtr(config.UpdatePolicyResolvable), ">=1.2.0 <1.5.0", packageData, "1.4.0"},
+		{"pre-release constraint", updatePolicyPtr(config.UpdatePolicyMajor), "^3.0.0-dev.0", packageData, "3.0.0-dev.1"},
+		{"nothing allowed", updatePolicyPtr(config.UpdatePolicyPatch), "^0.9.0", packageData, ""},
+		{
+			name:       "latest only",
+			policy:     updatePolicyPtr(config.UpdatePolicyMinor),
+			constraint: "^0.13.3",
+			packageData: &models.PackageWrapper{
+				Name:          "http",
+				LatestVersion: models.Package{Version: "0.13.5"},
+			},
+			expected: "0.13.5",
+		},
+		{
+			name:       "unparsable constraint",
+			policy:     updatePolicyPtr(config.UpdatePolicyPatch),
+			constraint: "^1.2",
+			packageData: &models.PackageWrapper{
+				Name:          "http",
+				LatestVersion: models.Package{Version: "2.0.0"},
+			},
+			expected: "",
+		},
+		{
+			name:       "unparsable constraint with major policy",
+			policy:     updatePolicyPtr(config.UpdatePolicyMajor),
+			constraint: "^1.2",
+			packageData: &models.PackageWrapper{
+				Name:          "http",
+				LatestVersion: models.Package{Version: "2.0.0"},
+			},
+			expected: "2.0.0",
+		},
+		{
+			name:       "minor policy on 0.x",
+			policy:     updatePolicyPtr(config.UpdatePolicyMinor),
+			constraint: "^0.13.3",
+			packageData: &models.PackageWrapper{
+				Name:          "http",
+				LatestVersion: models.Package{Version: "0.14.0"},
+				Versions:      []models.Package{{Version: "0.13.6"}, {Version: "0.14.0"}},
+			},
+			expected: "0.13.6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &UpdateService{
+				Config: &config.CLIConfig{
+					UpdatePolicy: tt.policy,
+				},
+			}
+			result := service.versionToUpdateTo(tt.constraint, tt.packageData, models.DependencyAnnotation{})
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestUpdateService_VersionToUpdateTo_PackageRules(t *testing.T) {
+	packageData := &models.PackageWrapper{
+		Name:          "http",
+		LatestVersion: models.Package{Version: "3.0.0"},
+		Versions: []models.Package{
+			{Version: "1.2.0"},
+			{Version: "1.4.0"},
+			{Version: "2.0.0"},
+			{Version: "2.3.0"},
+			{Version: "3.0.0"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		rule       config.PackageRule
+		constraint string
+		expected   string
+	}{
+		{name: "no rule", rule: config.PackageRule{}, expected: "3.0.0"},
+		{name: "max major", rule: config.PackageRule{MaxMajor: intPtr(2)}, expected: "2.3.0"},
+		{name: "pinned range", rule: config.PackageRule{Pin: "^1.2.0"}, expected: "1.4.0"},
+		{name: "pinned version", rule: config.PackageRule{Pin: "1.2.0"}, expected: "1.2.0"},
+		{name: "pin and max major", rule: config.PackageRule{Pin: ">=2.0.0", MaxMajor: intPtr(2)}, expected: "2.3.0"},
+		{name: "nothing allowed", rule: config.PackageRule{MaxMajor: intPtr(0)}, expected: ""},
+		{name: "unparsable constraint", rule: config.PackageRule{}, constraint: "^1.2", expected: "3.0.0"},
+		{name: "unparsable constraint above max major", rule: config.PackageRule{MaxMajor: intPtr(2)}, constraint: "^1.2", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &UpdateService{
+				Config: &config.CLIConfig{
+					PackageRules: map[string]config.PackageRule{"http": tt.rule},
+				},
+			}
+			constraint := tt.constraint
+			if constraint == "" {
+				constraint = "^1.2.0"
+			}
+			assert.Equal(t, tt.expected, service.versionToUpdateTo(constraint, packageData, models.DependencyAnnotation{}))
+		})
+	}
+}
+
+func TestUpdateService_IsPackageSelected_IgnoredPackages(t *testing.T) {
+	includes := []string{"http"}
+	service := &UpdateService{
+		Config: &config.CLIConfig{
+			IncludePackages: &includes,
+			PackageRules: map[string]config.PackageRule{
+				"http_parser": {Ignore: true},
+				"path":        {Pin: "^1.0.0"},
+			},
+		},
+	}
+
+	assert.True(t, service.isPackageSelected("http"))
+	assert.False(t, service.isPackageSelected("http_parser"))
+
+	service.Config.IncludePackages = nil
+	assert.True(t, service.isPackageSelected("path"))
+}
+
+// Helper function to create hosted dependencies from package names
+func hostedDependencies(names ...string) []models.Dependency {
+	var dependencies []models.Dependency
+	for _, name := range names {
+		dependencies = append(dependencies, models.Dependency{
+			Name:       name,
+			Source:     models.HostedSource,
+			Constraint: "any",
+		})
+	}
+	return dependencies
+}
+
+// Helper function to create a pointer to an update policy
+func updatePolicyPtr(policy config.UpdatePolicy) *config.UpdatePolicy {
+	return &policy
+}
+
+func TestCompareWithConstraint(t *testing.T) {
+	tests := []struct {
+		name               string
+		constraint         string
+		latest             string
+		expectedCurrent    string
+		expectedIsOutdated bool
+	}{
+		{"caret behind", "^0.13.3", "0.13.5", "0.13.3", true},
+		{"caret up to date", "^0.13.3", "0.13.3", "0.13.3", false},
+		{"numeric ordering", "^1.9.0", "1.10.0", "1.9.0", true},
+		{"pinned newer than latest", "^1.10.0", "1.9.0", "1.10.0", false},
+		{"range behind", ">=1.0.0 <2.0.0", "2.1.0", "1.0.0", true},
+		{"quoted range", "'>=2.12.0 <3.0.0'", "3.5.0", "2.12.0", true},
+		{"exact pin behind", "1.2.3", "1.2.4", "1.2.3", true},
+		{"pre-release latest", "^2.0.0", "2.0.0-dev.1", "2.0.0", false},
+		{"build metadata", "1.0.0", "1.0.0+1", "1.0.0", true},
+		{"upper bound only allows latest", "<2.0.0", "1.5.0", "2.0.0", false},
+		{"upper bound only excludes latest", "<2.0.0", "2.1.0", "2.0.0", true},
+		{"any", "any", "5.0.0", "any", false},
+		{"unparsable constraint", "^1.2", "1.3.0", "1.2", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, isOutdated := compareWithConstraint(tt.constraint, tt.latest)
+			assert.Equal(t, tt.expectedCurrent, current)
+			assert.Equal(t, tt.expectedIsOutdated, isOutdated)
+		})
+	}
+}
+
 func TestUpdateService_ProduceSliceOfDependencyUpdates(t *testing.T) {
 	tests := []struct {
 		name                  string
-		dependenciesToUpdate  []string
+		dependenciesToUpdate  []models.Dependency
 		dependencyDataFromAPI []*models.PackageWrapper
 		mockPubspec           *models.Pubspec
 		expected              []models.DependencyUpdate
 	}{
 		{
 			name:                 "update needed",
-			dependenciesToUpdate: []string{"http"},
+			dependenciesToUpdate: hostedDependencies("http"),
 			dependencyDataFromAPI: []*models.PackageWrapper{
 				{
 					Name: "http",
@@ -628,12 +1233,14 @@ func TestUpdateService_ProduceSliceOfDependencyUpdates(t *testing.T) {
 					Name:           "http",
 					CurrentVersion: "0.13.3",
 					LatestVersion:  "0.13.5",
+					Constraint:     "^0.13.3",
+					Section:        models.DependenciesSection,
 				},
 			},
 		},
 		{
 			name:                 "no update needed - same version",
-			dependenciesToUpdate: []string{"http"},
+			dependenciesToUpdate: hostedDependencies("http"),
 			dependencyDataFromAPI: []*models.PackageWrapper{
 				{
 					Name: "http",
@@ -649,9 +1256,79 @@ func TestUpdateService_ProduceSliceOfDependencyUpdates(t *testing.T) {
 			},
 			expected: nil,
 		},
+		{
+			name:                 "no update needed - pinned newer than latest",
+			dependenciesToUpdate: hostedDependencies("http"),
+			dependencyDataFromAPI: []*models.PackageWrapper{
+				{
+					Name: "http",
+					LatestVersion: models.Package{
+						Version: "1.9.0",
+					},
+				},
+			},
+			mockPubspec: &models.Pubspec{
+				Dependencies: map[string]any{
+					"http": "^1.10.0",
+				},
+			},
+			expected: nil,
+		},
+		{
+			name:                 "dev dependencies and overrides",
+			dependenciesToUpdate: hostedDependencies("http", "lints"),
+			dependencyDataFromAPI: []*models.PackageWrapper{
+				{
+					Name: "http",
+					LatestVersion: models.Package{
+						Version: "1.2.0",
+					},
+				},
+				{
+					Name: "lints",
+					LatestVersion: models.Package{
+						Version: "5.0.0",
+					},
+				},
+			},
+			mockPubspec: &models.Pubspec{
+				Dependencies: map[string]any{
+					"http": "^1.1.0",
+				},
+				DevDependencies: map[string]any{
+					"lints": "^4.0.0",
+				},
+				DependencyOverrides: map[string]any{
+					"http": "1.0.0",
+				},
+			},
+			expected: []models.DependencyUpdate{
+				{
+					Name:           "http",
+					CurrentVersion: "1.1.0",
+					LatestVersion:  "1.2.0",
+					Constraint:     "^1.1.0",
+					Section:        models.DependenciesSection,
+				},
+				{
+					Name:           "http",
+					CurrentVersion: "1.0.0",
+					LatestVersion:  "1.2.0",
+					Constraint:     "1.0.0",
+					Section:        models.DependencyOverridesSection,
+				},
+				{
+					Name:           "lints",
+					CurrentVersion: "4.0.0",
+					LatestVersion:  "5.0.0",
+					Constraint:     "^4.0.0",
+					Section:        models.DevDependenciesSection,
+				},
+			},
+		},
 		{
 			name:                 "multiple dependencies",
-			dependenciesToUpdate: []string{"http", "path"},
+			dependenciesToUpdate: hostedDependencies("http", "path"),
 			dependencyDataFromAPI: []*models.PackageWrapper{
 				{
 					Name: "http",
@@ -677,11 +1354,15 @@ func TestUpdateService_ProduceSliceOfDependencyUpdates(t *testing.T) {
 					Name:           "http",
 					CurrentVersion: "0.13.3",
 					LatestVersion:  "0.13.5",
+					Constraint:     "^0.13.3",
+					Section:        models.DependenciesSection,
 				},
 				{
 					Name:           "path",
 					CurrentVersion: "1.8.0",
 					LatestVersion:  "1.8.3",
+					Constraint:     "^1.8.0",
+					Section:        models.DependenciesSection,
 				},
 			},
 		},
@@ -701,11 +1382,11 @@ func TestUpdateService_ProduceSliceOfDependencyUpdates(t *testing.T) {
 			}
 
 			// Act
-			result := service.produceSliceOfDependencyUpdates(tt.dependenciesToUpdate, tt.dependencyDataFromAPI)
+			result, _ := service.produceSliceOfDependencyUpdates(tt.dependenciesToUpdate, tt.dependencyDataFromAPI)
 
 			// Sort both slices to ensure consistent comparison
 			sortDependencyUpdates := func(updates []models.DependencyUpdate) {
-				sort.Slice(updates, func(i, j int) bool {
+				sort.SliceStable(updates, func(i, j int) bool {
 					return updates[i].Name < updates[j].Name
 				})
 			}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sunderee/puby/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PUBSPEC_FILE_NAME  = "pubspec.yaml"
+	LOCKFILE_FILE_NAME = "pubspec.lock"
+	MELOS_FILE_NAME    = "melos.yaml"
+)
+
+// skippedDirectories are never searched for projects, as they only hold build
+// output and installed dependencies. Hidden directories are skipped as well.
+var skippedDirectories = map[string]bool{
+	"build":        true,
+	"node_modules": true,
+}
+
+// melosConfig is the part of melos.yaml that lists the packages of a repository
+type melosConfig struct {
+	Packages []string `yaml:"packages"`
+	Ignore   []string `yaml:"ignore"`
+}
+
+// DiscoverPubspecs returns the paths of the pubspec.yaml files of every project
+// below the root directory, sorted, starting with the root's own if it has
+// one. If the root pubspec.yaml declares a pub workspace, only its packages are
+// returned; otherwise, if there is a melos.yaml, only the packages it lists.
+// Without either, every project found is returned.
+func DiscoverPubspecs(rootDir string) ([]string, error) {
+	patterns, ignored, err := workspacePatterns(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var pubspecPaths []string
+	err = filepath.WalkDir(rootDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if filePath != rootDir && (strings.HasPrefix(entry.Name(), ".") || skippedDirectories[entry.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Name() != PUBSPEC_FILE_NAME {
+			return nil
+		}
+
+		relativeDir, err := filepath.Rel(rootDir, filepath.Dir(filePath))
+		if err != nil {
+			return err
+		}
+		relativeDir = filepath.ToSlash(relativeDir)
+
+		isMember := patterns == nil || matchesAnyGlob(patterns, relativeDir)
+		if relativeDir == "." || (isMember && !matchesAnyGlob(ignored, relativeDir)) {
+			pubspecPaths = append(pubspecPaths, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s for projects: %v", rootDir, err)
+	}
+
+	sort.Slice(pubspecPaths, func(i, j int) bool {
+		return filepath.Dir(pubspecPaths[i]) < filepath.Dir(pubspecPaths[j])
+	})
+
+	return pubspecPaths, nil
+}
+
+// workspacePatterns returns the glob patterns of the project directories of a
+// pub workspace or melos repository, relative to the root directory, and the
+// patterns of the directories to leave out. The patterns are nil if the root
+// is neither.
+func workspacePatterns(rootDir string) ([]string, []string, error) {
+	content, err := os.ReadFile(filepath.Join(rootDir, PUBSPEC_FILE_NAME))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read %s: %v", PUBSPEC_FILE_NAME, err)
+	}
+	if err == nil {
+		var pubspec models.Pubspec
+		if err := yaml.Unmarshal(content, &pubspec); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %v", PUBSPEC_FILE_NAME, err)
+		}
+		if len(pubspec.Workspace) > 0 {
+			return cleanGlobs(pubspec.Workspace), nil, nil
+		}
+	}
+
+	content, err = os.ReadFile(filepath.Join(rootDir, MELOS_FILE_NAME))
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %v", MELOS_FILE_NAME, err)
+	}
+
+	var melos melosConfig
+	if err := yaml.Unmarshal(content, &melos); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %v", MELOS_FILE_NAME, err)
+	}
+	if len(melos.Packages) == 0 {
+		return nil, nil, nil
+	}
+
+	return cleanGlobs(melos.Packages), cleanGlobs(melos.Ignore), nil
+}
+
+// cleanGlobs normalizes glob patterns to slash-separated paths without a
+// leading "./" or trailing slash
+func cleanGlobs(patterns []string) []string {
+	cleaned := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		cleaned = append(cleaned, path.Clean(filepath.ToSlash(pattern)))
+	}
+	return cleaned
+}
+
+// matchesAnyGlob reports whether the slash-separated path matches any of the
+// patterns
+func matchesAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(strings.Split(pattern, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches path segments against pattern segments. A "**" segment
+// matches any number of segments, including none; other segments are matched
+// with path.Match.
+func matchGlob(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlob(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+
+	return matchGlob(pattern[1:], segments[1:])
+}
+
+// FindLockfile returns the path of the pubspec.lock the project at the given
+// directory is resolved with: its own, or that of the closest directory above
+// it up to the root, as packages of a pub workspace share the root's lockfile.
+// If there is none, the path it would have in the project directory is
+// returned.
+func FindLockfile(projectDir, rootDir string) string {
+	for dir := projectDir; ; dir = filepath.Dir(dir) {
+		lockfilePath := filepath.Join(dir, LOCKFILE_FILE_NAME)
+		if _, err := os.Stat(lockfilePath); err == nil {
+			return lockfilePath
+		}
+
+		if dir == rootDir || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	return filepath.Join(projectDir, LOCKFILE_FILE_NAME)
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates the files below the directory, creating missing
+// directories along the way
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	}
+}
+
+// relativePaths returns the paths relative to the directory, slash-separated
+func relativePaths(t *testing.T, dir string, paths []string) []string {
+	t.Helper()
+	var result []string
+	for _, path := range paths {
+		relativePath, err := filepath.Rel(dir, path)
+		require.NoError(t, err)
+		result = append(result, filepath.ToSlash(relativePath))
+	}
+	return result
+}
+
+func TestDiscoverPubspecs(t *testing.T) {
+	t.Run("Every project", func(t *testing.T) {
+		rootDir := t.TempDir()
+		writeFiles(t, rootDir, map[string]string{
+			"pubspec.yaml":                            "name: root\n",
+			"packages/core/pubspec.yaml":              "name: core\n",
+			"packages/core/example/pubspec.yaml":      "name: example\n",
+			"apps/mobile/pubspec.yaml":                "name: mobile\n",
+			"apps/mobile/build/app/pubspec.yaml":      "name: build_output\n",
+			"apps/mobile/.dart_tool/pkg/pubspec.yaml": "name: tool_output\n",
+			"docs/README.md":                          "# Docs\n",
+		})
+
+		pubspecPaths, err := DiscoverPubspecs(rootDir)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"pubspec.yaml",
+			"apps/mobile/pubspec.yaml",
+			"packages/core/pubspec.yaml",
+			"packages/core/example/pubspec.yaml",
+		}, relativePaths(t, rootDir, pubspecPaths))
+	})
+
+	t.Run("Pub workspace", func(t *testing.T) {
+		rootDir := t.TempDir()
+		writeFiles(t, rootDir, map[string]string{
+			"pubspec.yaml":                       "name: root\nworkspace:\n  - packages/core\n  - apps/*\n",
+			"packages/core/pubspec.yaml":         "name: core\nresolution: workspace\n",
+			"packages/core/example/pubspec.yaml": "name: example\n",
+			"packages/legacy/pubspec.yaml":       "name: legacy\n",
+			"apps/mobile/pubspec.yaml":           "name: mobile\nresolution: workspace\n",
+		})
+
+		pubspecPaths, err := DiscoverPubspecs(rootDir)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"pubspec.yaml",
+			"apps/mobile/pubspec.yaml",
+			"packages/core/pubspec.yaml",
+		}, relativePaths(t, rootDir, pubspecPaths))
+	})
+
+	t.Run("Melos", func(t *testing.T) {
+		rootDir := t.TempDir()
+		writeFiles(t, rootDir, map[string]string{
+			"melos.yaml":                         "name: repo\npackages:\n  - packages/**\n  - apps/mobile\nignore:\n  - packages/**/example\n",
+			"packages/core/pubspec.yaml":         "name: core\n",
+			"packages/core/example/pubspec.yaml": "name: example\n",
+			"packages/ui/widgets/pubspec.yaml":   "name: widgets\n",
+			"apps/mobile/pubspec.yaml":           "name: mobile\n",
+			"apps/web/pubspec.yaml":              "name: web\n",
+		})
+
+		pubspecPaths, err := DiscoverPubspecs(rootDir)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"apps/mobile/pubspec.yaml",
+			"packages/core/pubspec.yaml",
+			"packages/ui/widgets/pubspec.yaml",
+		}, relativePaths(t, rootDir, pubspecPaths))
+	})
+
+	t.Run("Invalid workspace", func(t *testing.T) {
+		rootDir := t.TempDir()
+		writeFiles(t, rootDir, map[string]string{
+			"pubspec.yaml": "workspace: [\n",
+		})
+
+		_, err := DiscoverPubspecs(rootDir)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "packages/core", name: "packages/core", expected: true},
+		{pattern: "packages/*", name: "packages/core", expected: true},
+		{pattern: "packages/*", name: "packages/core/example", expected: false},
+		{pattern: "packages/**", name: "packages/core/example", expected: true},
+		{pattern: "packages/**", name: "packages", expected: true},
+		{pattern: "**/example", name: "packages/core/example", expected: true},
+		{pattern: "packages/**/example", name: "packages/example", expected: true},
+		{pattern: "apps/*", name: "packages/core", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchGlob(strings.Split(tt.pattern, "/"), strings.Split(tt.name, "/")))
+		})
+	}
+}
+
+func TestFindLockfile(t *testing.T) {
+	rootDir := t.TempDir()
+	writeFiles(t, rootDir, map[string]string{
+		"pubspec.lock":               "packages: {}\n",
+		"packages/core/pubspec.yaml": "name: core\n",
+		"apps/mobile/pubspec.lock":   "packages: {}\n",
+	})
+
+	assert.Equal(t, filepath.Join(rootDir, "pubspec.lock"), FindLockfile(filepath.Join(rootDir, "packages", "core"), rootDir))
+	assert.Equal(t, filepath.Join(rootDir, "apps", "mobile", "pubspec.lock"), FindLockfile(filepath.Join(rootDir, "apps", "mobile"), rootDir))
+
+	// Without any lockfile up to the root, the project's own path is returned
+	otherDir := t.TempDir()
+	assert.Equal(t, filepath.Join(otherDir, "app", "pubspec.lock"), FindLockfile(filepath.Join(otherDir, "app"), otherDir))
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/sunderee/puby/internal/models"
+)
+
+// CheckWorkspace checks every project for updates, one after the other, using
+// the update service newUpdateService creates for its pubspec.yaml. Sharing an
+// API service between those update services makes every package be looked up
+// once for all projects. A project that can't be checked is reported with its
+// error and doesn't stop the others.
+func CheckWorkspace(ctx context.Context, rootDir string, pubspecPaths []string, newUpdateService func(pubspecPath string) *UpdateService) (*models.WorkspaceUpdate, error) {
+	workspaceUpdate := &models.WorkspaceUpdate{RootDir: rootDir}
+
+	for _, pubspecPath := range pubspecPaths {
+		relativePath, err := filepath.Rel(rootDir, pubspecPath)
+		if err != nil {
+			relativePath = pubspecPath
+		}
+
+		update, err := newUpdateService(pubspecPath).CheckForUpdatesWithContext(ctx)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		workspaceUpdate.Projects = append(workspaceUpdate.Projects, models.ProjectUpdate{
+			PubspecPath: filepath.ToSlash(relativePath),
+			Update:      update,
+			Err:         err,
+		})
+	}
+
+	return workspaceUpdate, nil
+}
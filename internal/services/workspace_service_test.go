@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/sunderee/puby/internal/config"
+	"github.com/sunderee/puby/internal/models"
+	"github.com/sunderee/puby/internal/parsers"
+)
+
+func TestCheckWorkspace(t *testing.T) {
+	rootDir := t.TempDir()
+	pubspecs := map[string]*models.Pubspec{
+		filepath.Join(rootDir, "apps", "mobile", "pubspec.yaml"): {
+			Dependencies: map[string]any{"http": "^1.0.0", "path": "^1.8.0"},
+		},
+		filepath.Join(rootDir, "packages", "core", "pubspec.yaml"): {
+			Dependencies: map[string]any{"http": "^1.1.0"},
+		},
+	}
+	brokenPubspecPath := filepath.Join(rootDir, "packages", "broken", "pubspec.yaml")
+
+	var packageCalls atomic.Int32
+	apiService := NewSharedAPIService(&MockAPIService{
+		GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+			return nil, ErrNotCached
+		},
+		GetPackageFunc: func(packageName string) (*models.PackageWrapper, error) {
+			packageCalls.Add(1)
+			return &models.PackageWrapper{
+				Name:          packageName,
+				LatestVersion: models.Package{Version: "1.2.0"},
+			}, nil
+		},
+	})
+
+	newUpdateService := func(pubspecPath string) *UpdateService {
+		service := NewUpdateService(&parsers.MockPubspecParser{
+			ParseFunc: func() (*models.Pubspec, error) {
+				if pubspec, ok := pubspecs[pubspecPath]; ok {
+					return pubspec, nil
+				}
+				return nil, errors.New("failed to parse pubspec.yaml")
+			},
+		}, apiService)
+		service.Config = &config.CLIConfig{Concurrency: intPtr(1)}
+		return service
+	}
+
+	workspaceUpdate, err := CheckWorkspace(context.Background(), rootDir, []string{
+		filepath.Join(rootDir, "apps", "mobile", "pubspec.yaml"),
+		brokenPubspecPath,
+		filepath.Join(rootDir, "packages", "core", "pubspec.yaml"),
+	}, newUpdateService)
+
+	require.NoError(t, err)
+	assert.Equal(t, rootDir, workspaceUpdate.RootDir)
+	require.Len(t, workspaceUpdate.Projects, 3)
+
+	// Every package is looked up once, however many projects depend on it
+	assert.Equal(t, int32(2), packageCalls.Load())
+
+	mobile := workspaceUpdate.Projects[0]
+	assert.Equal(t, "apps/mobile/pubspec.yaml", mobile.PubspecPath)
+	assert.NoError(t, mobile.Err)
+	assert.Len(t, mobile.Update.DependencyUpdates, 1)
+
+	broken := workspaceUpdate.Projects[1]
+	assert.Equal(t, "packages/broken/pubspec.yaml", broken.PubspecPath)
+	assert.EqualError(t, broken.Err, "failed to parse pubspec.yaml")
+	assert.Nil(t, broken.Update)
+
+	core := workspaceUpdate.Projects[2]
+	assert.Equal(t, "packages/core/pubspec.yaml", core.PubspecPath)
+	assert.Len(t, core.Update.DependencyUpdates, 1)
+
+	summary := workspaceUpdate.Summary()
+	assert.Equal(t, 3, summary.Projects)
+	assert.Equal(t, 2, summary.ProjectsWithUpdates)
+	assert.Equal(t, 1, summary.ProjectsWithErrors)
+	assert.Equal(t, 2, summary.Updates)
+	assert.Equal(t, map[models.UpdateKind]int{models.UpdateKindMinor: 2}, summary.UpdatesByKind)
+}
+
+func TestCheckWorkspace_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := CheckWorkspace(ctx, "/repo", []string{"/repo/pubspec.yaml"}, func(pubspecPath string) *UpdateService {
+		service := NewUpdateService(&parsers.MockPubspecParser{
+			ParseFunc: func() (*models.Pubspec, error) {
+				return &models.Pubspec{}, nil
+			},
+		}, &MockAPIService{
+			GetSDKReleaseFunc: func() (*models.SDKReleaseWrapper, error) {
+				return nil, ErrNotCached
+			},
+		})
+		service.Config = &config.CLIConfig{}
+		return service
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}